@@ -0,0 +1,38 @@
+package gospec
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// NewIdentifierASCII is like NewIdentifier, but folds the supplied
+// string to ASCII before it is parsed so that every form of the
+// Identifier is pure ASCII. Diacritics are removed from the letters
+// they decorate (e.g. "café" -> "cafe"), and any other non-ASCII
+// letters and digits are dropped. The Source retains the original
+// string.
+func NewIdentifierASCII(s string) (*Identifier, error) {
+	return NewIdentifierWithOptions(s, Options{ASCIIFold: true})
+}
+
+// foldASCII decomposes the given string and removes all of its
+// non-ASCII runes. Non-ASCII spaces and punctuation are replaced
+// with a space so that they continue to act as word boundaries.
+func foldASCII(s string) string {
+	var sb strings.Builder
+	for _, r := range norm.NFD.String(s) {
+		switch {
+		case r < utf8.RuneSelf:
+			sb.WriteRune(r)
+		case unicode.In(r, unicode.Letter, unicode.Number, unicode.Mark):
+			// Drop combining marks, and any letters and
+			// digits that don't have an ASCII equivalent.
+		default:
+			sb.WriteRune(' ')
+		}
+	}
+	return sb.String()
+}
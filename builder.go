@@ -0,0 +1,50 @@
+package gospec
+
+import "strings"
+
+// IdentifierBuilder accumulates words to build an Identifier
+// without parsing them from a single string. The zero value
+// is ready to use.
+//
+//	var b IdentifierBuilder
+//	b.Append(prefix)
+//	b.Append("ID")
+//	b.Append("Handler")
+//	id, err := b.Build()
+type IdentifierBuilder struct {
+	words []string
+}
+
+// Append adds the given word to the Identifier. The word is
+// lowercased, but is otherwise added verbatim as a single word;
+// it is not split at case or punctuation boundaries. Empty words
+// are ignored.
+func (b *IdentifierBuilder) Append(word string) {
+	if word = strings.ToLower(strings.TrimSpace(word)); word != "" {
+		b.words = append(b.words, word)
+	}
+}
+
+// Build returns an Identifier for the accumulated words, where the
+// natural form of the words is used as its Source. An error is
+// returned if no words have been appended.
+func (b *IdentifierBuilder) Build() (*Identifier, error) {
+	return NewIdentifierFromWords(b.words)
+}
+
+// NewIdentifierFromWords returns an Identifier for the given words,
+// which are used as they are rather than being parsed: each word is
+// lowercased and trimmed, but is not split at case or punctuation
+// boundaries. Empty words are ignored. The natural form of the words
+// is used as the Source, and an error is returned if there are no
+// words.
+//
+//	NewIdentifierFromWords([]string{"user", "id"}) -> Pascal "UserID"
+//	NewIdentifierFromWords([]string{"e-mail"})     -> Pascal "E-mail"
+func NewIdentifierFromWords(words []string) (*Identifier, error) {
+	var b IdentifierBuilder
+	for _, word := range words {
+		b.Append(word)
+	}
+	return newIdentifier(natural(b.words), b.words, casing{initialisms: _commonInitialisms})
+}
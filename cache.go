@@ -0,0 +1,34 @@
+package gospec
+
+import "sync"
+
+// _identifiers caches the Identifiers returned by NewIdentifierCached,
+// keyed by their source string.
+var _identifiers sync.Map // string -> *Identifier
+
+// NewIdentifierCached is like NewIdentifier, but memoizes the
+// Identifier for each string, so that repeatedly converting the
+// same strings (e.g. from a template) parses each of them once.
+// A copy of the memoized Identifier is returned, so callers are
+// free to modify it. Errors are not memoized.
+//
+// The cache is shared by the entire process and is never evicted,
+// so it should only be used for a bounded set of strings. It is
+// safe for concurrent use.
+func NewIdentifierCached(s string) (*Identifier, error) {
+	if cached, ok := _identifiers.Load(s); ok {
+		return cached.(*Identifier).clone(), nil
+	}
+	id, err := NewIdentifier(s)
+	if err != nil {
+		return nil, err
+	}
+	_identifiers.Store(s, id.clone())
+	return id, nil
+}
+
+// clone returns a deep copy of the Identifier.
+func (i Identifier) clone() *Identifier {
+	i.Words = append([]string(nil), i.Words...)
+	return &i
+}
@@ -0,0 +1,58 @@
+package gospec
+
+import "strings"
+
+// CommonExtensions returns the file extensions that are removed
+// by TrimExtension when no extensions are given. A new slice is
+// returned on every call, so callers are free to extend it with
+// their own extensions.
+func CommonExtensions() []string {
+	extensions := make([]string, len(_commonExtensions))
+	copy(extensions, _commonExtensions)
+	return extensions
+}
+
+// TrimExtension removes a trailing file extension from the given
+// string, so that it isn't parsed as part of an Identifier. The
+// extensions are matched case-insensitively, with or without a
+// leading dot, and the longest matching extension is removed. If no
+// extensions are given, the CommonExtensions are used. The string
+// is returned unchanged if nothing would precede the extension.
+//
+//	TrimExtension("config.yaml")             -> "config"
+//	TrimExtension("user.schema.json")        -> "user.schema"
+//	TrimExtension("backup.tar.gz", "tar.gz") -> "backup"
+func TrimExtension(s string, exts ...string) string {
+	if len(exts) == 0 {
+		exts = _commonExtensions
+	}
+	var trimmed string
+	for _, ext := range exts {
+		ext = "." + strings.TrimLeft(ext, ".")
+		if ext == "." || len(ext) >= len(s) {
+			continue
+		}
+		if i := len(s) - len(ext); strings.EqualFold(s[i:], ext) && (trimmed == "" || i < len(trimmed)) {
+			trimmed = s[:i]
+		}
+	}
+	if trimmed == "" {
+		return s
+	}
+	return trimmed
+}
+
+// _commonExtensions is the default set of file extensions.
+var _commonExtensions = []string{
+	"csv",
+	"go",
+	"graphql",
+	"json",
+	"proto",
+	"sql",
+	"toml",
+	"txt",
+	"xml",
+	"yaml",
+	"yml",
+}
@@ -0,0 +1,65 @@
+package gospec
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+)
+
+// ParseError is returned when a buffer cannot be parsed as Go code.
+// It wraps the error reported by the parser, which is usually a
+// scanner.ErrorList, so that callers can recover the position of
+// each syntax error:
+//
+//	var list scanner.ErrorList
+//	if errors.As(err, &list) {
+//	  for _, e := range list {
+//	    fmt.Println(e.Pos.Line, e.Pos.Column, e.Msg)
+//	  }
+//	}
+type ParseError struct {
+	Err error
+}
+
+// Error implements the error interface.
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("failed to parse Go code: %v", e.Err)
+}
+
+// Unwrap returns the underlying parser error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Format parses the buffer, interpreting it as Go code, and
+// formats it in the canonical gofmt style.
+func Format(filename string, buf []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parseFile(fset, filename, buf)
+	if err != nil {
+		return nil, err
+	}
+	return formatFile(fset, f)
+}
+
+// parseFile parses the buffer as a Go source file, retaining
+// its comments. Parse failures are reported as a *ParseError.
+func parseFile(fset *token.FileSet, filename string, buf []byte) (*ast.File, error) {
+	f, err := parser.ParseFile(fset, filename, buf, parser.ParseComments)
+	if err != nil {
+		return nil, &ParseError{Err: err}
+	}
+	return f, nil
+}
+
+// formatFile formats the given file in the canonical gofmt style.
+func formatFile(fset *token.FileSet, f *ast.File) ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := format.Node(&buffer, fset, f); err != nil {
+		return nil, fmt.Errorf("failed to format Go code: %v", err)
+	}
+	return buffer.Bytes(), nil
+}
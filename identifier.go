@@ -1,100 +1,744 @@
 package gospec
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
+	"sync"
 	"unicode"
+	"unicode/utf8"
 )
 
 // Identifier represents a Go identifier in a variety of common
-// case conventions.
+// case conventions. Words holds the lowercase words parsed from
+// the Source, from which every case convention is derived when
+// the Identifier is created. The zero value has no words, and
+// every form is empty.
 type Identifier struct {
-	Camel   string
-	Kebab   string
-	Natural string
-	Package string
-	Pascal  string
-	Snake   string
-	Source  string
+	Camel    string
+	Constant string
+	Dot      string
+	Kebab    string
+	Natural  string
+	Package  string
+	Pascal   string
+	Snake    string
+	Source   string
+	Title    string
+	Train    string
+	Words    []string
+
+	// casing is retained so that Identifiers derived from
+	// this one are cased consistently.
+	casing casing
+}
+
+// casing determines how words are capitalized in the Camel,
+// Pascal, and Title forms, and the prefix of every form.
+type casing struct {
+	initialisms map[string]bool
+	literals    map[string]string // lowercase word -> literal
+	prefix      string            // prepended to every form
+}
+
+// capitalize returns the capitalized form of the given word.
+// Literals take precedence over initialisms.
+func (c casing) capitalize(word string) string {
+	if literal, ok := c.literals[word]; ok {
+		return literal
+	}
+	return capitalize(word, c.initialisms)
 }
 
 // NewIdentifier parses the supplied string into an Identifier.
 // Capital letters, whitespace, and punctuation are treated as
-// word boundaries.
+// word boundaries, so the string need not be a valid Go
+// identifier; an error is only returned if it does not contain
+// any letters or digits, e.g. if it is empty, whitespace, or
+// punctuation such as "---". The Source is the supplied string
+// exactly, including any surrounding whitespace. Words that match
+// one of the CommonInitialisms are upper-cased in the Camel,
+// Pascal, and Title forms.
+//
+// Digits are kept as they are, so a string that begins with a digit
+// (or consists only of digits) produces forms that begin with a
+// digit, and are not legal Go identifiers. Use Valid, Exported, or
+// Unexported to obtain a legal Go identifier:
+//
+//	NewIdentifier("2020").Pascal         -> "2020"
+//	NewIdentifier("2020").Valid().Pascal -> "_2020"
+//	NewIdentifier("year2020").Pascal     -> "Year2020"
 func NewIdentifier(s string) (*Identifier, error) {
-	if !isValidIdentifier(s) {
-		return nil, fmt.Errorf("%q is not a valid Go identifier", s)
+	return NewIdentifierWithOptions(s, Options{})
+}
+
+// NewIdentifierWithInitialisms is like NewIdentifier, but
+// upper-cases the words in the given set of initialisms instead
+// of the CommonInitialisms. The set is keyed by the upper-case
+// form of each initialism (e.g. "ID").
+//
+//	initialisms := CommonInitialisms()
+//	initialisms["SKU"] = true
+//	NewIdentifierWithInitialisms("productSku", initialisms) -> "ProductSKU"
+func NewIdentifierWithInitialisms(s string, initialisms map[string]bool) (*Identifier, error) {
+	if initialisms == nil {
+		// A nil set has always meant no initialisms, whereas
+		// the Options default to the CommonInitialisms.
+		initialisms = map[string]bool{}
+	}
+	return NewIdentifierWithOptions(s, Options{Initialisms: initialisms})
+}
+
+// NewIdentifierPreservingUnderscore is like NewIdentifier, but if
+// the supplied string begins with an underscore, a single leading
+// underscore is preserved in every form of the Identifier. The
+// underscore is not included in the Words.
+//
+//	NewIdentifierPreservingUnderscore("_internalValue") -> Camel "_internalValue", Snake "_internal_value"
+func NewIdentifierPreservingUnderscore(s string) (*Identifier, error) {
+	return NewIdentifierWithOptions(s, Options{PreserveUnderscore: true})
+}
+
+// NewIdentifierWithReplacements is like NewIdentifier, but replaces
+// every parsed word that matches a key in the given replacements
+// (case-insensitively) with the words of its value before the case
+// conventions are computed.
+//
+//	repl := map[string]string{"identifier": "id"}
+//	NewIdentifierWithReplacements("userIdentifier", repl) -> "userID"
+func NewIdentifierWithReplacements(s string, replacements map[string]string) (*Identifier, error) {
+	return NewIdentifierWithOptions(s, Options{Replacements: replacements})
+}
+
+// newIdentifier returns an Identifier for the words parsed from
+// the given source string.
+func newIdentifier(source string, words []string, c casing) (*Identifier, error) {
+	if len(words) == 0 {
+		return nil, fmt.Errorf("%q does not contain any words", source)
 	}
-	words := parse(s)
+	p := c.prefix
 	return &Identifier{
-		Camel:   camel(words),
-		Kebab:   kebab(words),
-		Natural: natural(words),
-		Package: packge(words),
-		Pascal:  pascal(words),
-		Snake:   snake(words),
-		Source:  s,
+		Camel:    p + camel(words, c),
+		Constant: p + constant(words),
+		Dot:      p + dot(words),
+		Kebab:    p + kebab(words),
+		Natural:  p + natural(words),
+		Package:  p + packge(words),
+		Pascal:   p + pascal(words, c),
+		Snake:    p + snake(words),
+		Source:   source,
+		Title:    p + titleCase(words, c),
+		Train:    p + train(words, c),
+		Words:    words,
+
+		casing: c,
 	}, nil
 }
 
-// isValidIdentifier determines if the given string
-// represents a valid Go identifier.
+// withWords returns a new Identifier for the given words, cased
+// consistently with this one. The natural form of the words is
+// used as its Source.
+func (i Identifier) withWords(words []string) *Identifier {
+	id, err := newIdentifier(natural(words), words, i.casing)
+	if err != nil {
+		return &i
+	}
+	return id
+}
+
+// String returns the source string the Identifier was parsed from.
+func (i Identifier) String() string {
+	return i.Source
+}
+
+// MarshalJSON implements json.Marshaler. The Identifier is encoded
+// as its Source string.
+func (i Identifier) MarshalJSON() ([]byte, error) {
+	return json.Marshal(i.Source)
+}
+
+// UnmarshalJSON implements json.Unmarshaler. The Identifier is
+// decoded from a string, and every case convention is recomputed
+// with NewIdentifier. As with other types, null is a no-op, and the
+// empty string decodes to the zero Identifier, so that a zero value
+// round-trips through MarshalJSON.
+func (i *Identifier) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if s == "" {
+		*i = Identifier{}
+		return nil
+	}
+	id, err := NewIdentifier(s)
+	if err != nil {
+		return err
+	}
+	*i = *id
+	return nil
+}
+
+// Case returns the variant of the Identifier in the given case
+// style, such as "camel", "pascal", or "snake". An error is
+// returned if the style is not recognized. See ParseStyle.
+func (i Identifier) Case(style string) (string, error) {
+	s, err := ParseStyle(style)
+	if err != nil {
+		return "", err
+	}
+	return i.In(s), nil
+}
+
+// Join returns the words of the Identifier, each transformed by
+// the given function, joined by the given separator. The function
+// is called with each lowercase word and its index. This can be
+// used to build case conventions other than the predefined forms,
+// which are prefixed in the same way (e.g. with a preserved
+// leading underscore):
 //
-//  := letter { letter | unicode_digit }
-func isValidIdentifier(s string) bool {
-	if len(s) == 0 {
+//	screaming := func(word string, _ int) string { return strings.ToUpper(word) }
+//	NewIdentifier("userAccount").Join(screaming, "_") -> "USER_ACCOUNT" (i.e. Constant)
+func (i Identifier) Join(transform func(word string, index int) string, sep string) string {
+	transformed := make([]string, len(i.Words))
+	for j, word := range i.Words {
+		transformed[j] = transform(word, j)
+	}
+	return i.casing.prefix + strings.Join(transformed, sep)
+}
+
+// Conflicts returns whether the Pascal or Camel form of the
+// Identifier is in the given set of reserved names, e.g. the
+// methods generated for the struct that a field belongs to.
+//
+//	reserved := map[string]bool{"String": true}
+//	NewIdentifier("string").Conflicts(reserved) -> true
+func (i Identifier) Conflicts(reserved map[string]bool) bool {
+	return reserved[i.Pascal] || reserved[i.Camel]
+}
+
+// Equal returns whether the Identifier consists of the same words
+// as the other, regardless of their original case convention.
+//
+//	NewIdentifier("FooBar").Equal(NewIdentifier("foo_bar")) -> true
+func (i Identifier) Equal(other Identifier) bool {
+	return equalWords(i.Words, other.Words)
+}
+
+// Equivalent returns whether the given strings split into the same
+// words, regardless of their case convention. This is useful for
+// verifying that a conversion round-trips.
+//
+//	Equivalent("FooBar", "foo_bar")        -> true
+//	Equivalent("HTTPServer", "http-server") -> true
+//	Equivalent("FooBar", "foobar")         -> false
+func Equivalent(a, b string) bool {
+	return equalWords(parse(a), parse(b))
+}
+
+// equalWords returns whether the word slices are equal under
+// Unicode case folding.
+func equalWords(a, b []string) bool {
+	if len(a) != len(b) {
 		return false
 	}
-	for i, r := range s {
-		if (i == 0 && !unicode.IsLetter(r)) || (r != '_' && !unicode.In(r, unicode.Letter, unicode.Digit)) {
+	for i, word := range a {
+		if !strings.EqualFold(word, b[i]) {
 			return false
 		}
 	}
 	return true
 }
 
+// FileName returns the words of the Identifier joined by the given
+// separator, followed by the given extension. The extension is
+// given a single leading dot, and is omitted if empty.
+//
+//	NewIdentifier("UserAccount").FileName("_", ".go") -> "user_account.go"
+//	NewIdentifier("UserAccount").FileName("-", "yaml") -> "user-account.yaml"
+func (i Identifier) FileName(sep, ext string) string {
+	name := strings.Join(i.Words, sep)
+	if ext = strings.TrimLeft(ext, "."); ext != "" {
+		name += "." + ext
+	}
+	return name
+}
+
+// JSONTag returns the name used for the Identifier in a `json:"..."`
+// struct tag, i.e. its Camel form.
+//
+//	NewIdentifier("user_id").JSONTag() -> "userID"
+func (i Identifier) JSONTag() string {
+	return i.Camel
+}
+
+// JSONTagSnake is like JSONTag, but returns the Snake form for
+// APIs that use snake_case field names.
+//
+//	NewIdentifier("UserID").JSONTagSnake() -> "user_id"
+func (i Identifier) JSONTagSnake() string {
+	return i.Snake
+}
+
+// EnvVar returns the Identifier as an environment variable name,
+// i.e. its Constant form, prefixed by the Constant form of the given
+// prefix (if any). A preserved leading underscore is never included.
+//
+//	NewIdentifier("serverPort").EnvVar("app")    -> "APP_SERVER_PORT"
+//	NewIdentifier("serverPort").EnvVar("my-app") -> "MY_APP_SERVER_PORT"
+//	NewIdentifier("serverPort").EnvVar("")       -> "SERVER_PORT"
+func (i Identifier) EnvVar(prefix string) string {
+	name := constant(i.Words)
+	if p := constant(parse(prefix)); p != "" {
+		return p + "_" + name
+	}
+	return name
+}
+
+// ProtoField returns the Identifier as a Protocol Buffers field
+// name, i.e. its Snake form restricted to the proto identifier
+// grammar. Any rune other than an ASCII lowercase letter or digit
+// is removed from each word, and the result is prefixed with
+// "field_" if it would otherwise not begin with a letter. A
+// preserved leading underscore is never included.
+//
+//	NewIdentifier("UserID").ProtoField() -> "user_id"
+//	NewIdentifier("2020").ProtoField()   -> "field_2020"
+//
+// For details, see https://protobuf.dev/reference/protobuf/proto3-spec/#identifiers.
+func (i Identifier) ProtoField() string {
+	words := make([]string, 0, len(i.Words))
+	for _, word := range i.Words {
+		word = strings.Map(func(r rune) rune {
+			if ('a' <= r && r <= 'z') || ('0' <= r && r <= '9') {
+				return r
+			}
+			return -1
+		}, word)
+		if word != "" {
+			words = append(words, word)
+		}
+	}
+	field := strings.Join(words, "_")
+	if field == "" || field[0] < 'a' || field[0] > 'z' {
+		field = "field_" + field
+	}
+	return strings.TrimSuffix(field, "_")
+}
+
+// Receiver returns a short, lowercase receiver name for methods
+// on the type named by the Identifier. The receiver consists of
+// the first letter of the first word, followed by the first letter
+// of the last word if there is more than one word:
+//
+//	Handler     -> "h"
+//	UserService -> "us"
+//	HTTPClient  -> "hc"
+//
+// If the result is a keyword (e.g. "go" for "GoOptions"), only the
+// first letter is used. Words without letters are skipped, and "r"
+// is returned if there are no letters at all.
+func (i Identifier) Receiver() string {
+	var initials []rune
+	for _, word := range i.Words {
+		if j := strings.IndexFunc(word, unicode.IsLetter); j >= 0 {
+			r, _ := utf8.DecodeRuneInString(word[j:])
+			initials = append(initials, unicode.ToLower(r))
+		}
+	}
+	switch len(initials) {
+	case 0:
+		return "r"
+	case 1:
+		return string(initials[0])
+	}
+	receiver := string([]rune{initials[0], initials[len(initials)-1]})
+	if IsKeyword(receiver) || IsPredeclared(receiver) {
+		return string(initials[0])
+	}
+	return receiver
+}
+
+// Abbreviate returns a copy of the Identifier with at most the given
+// number of words. If the Identifier has more words, the first
+// maxWords-1 words are kept, followed by the last word, which
+// usually names what the identifier is. A maxWords less than one
+// is treated as one, so only the last word is kept.
+//
+//	NewIdentifier("UserAccountBillingAddressValidator").Abbreviate(3) -> "UserAccountValidator"
+//	NewIdentifier("UserAccountBillingAddressValidator").Abbreviate(1) -> "Validator"
+//
+// Different Identifiers may be abbreviated to the same words, so
+// callers must ensure the results are unique if necessary.
+func (i Identifier) Abbreviate(maxWords int) *Identifier {
+	if maxWords < 1 {
+		maxWords = 1
+	}
+	if len(i.Words) <= maxWords {
+		return &i
+	}
+	words := make([]string, 0, maxWords)
+	words = append(words, i.Words[:maxWords-1]...)
+	words = append(words, i.Words[len(i.Words)-1])
+	return i.withWords(words)
+}
+
+// Valid returns a copy of the Identifier whose Go identifier forms
+// (Camel, Constant, Package, Pascal, and Snake) are prefixed with
+// an underscore if the Identifier begins with a digit, so that
+// each form is a legal Go identifier.
+//
+//	NewIdentifier("3dModel").Valid().Pascal -> "_3DModel"
+//	NewIdentifier("2020").Valid().Pascal    -> "_2020"
+//
+// Note that the prefixed Pascal form is not exported. Prepend a
+// word, such as "Year2020", if an exported name is needed.
+func (i Identifier) Valid() *Identifier {
+	i.Camel = guardDigit(i.Camel)
+	i.Constant = guardDigit(i.Constant)
+	i.Package = guardDigit(i.Package)
+	i.Pascal = guardDigit(i.Pascal)
+	i.Snake = guardDigit(i.Snake)
+	return &i
+}
+
+// Exported returns the exported (i.e. Pascal case) form of the
+// Identifier. If the Identifier begins with a digit, it is
+// prefixed with an underscore so that it is a legal Go identifier.
+// A preserved leading underscore is never included, since the name
+// would not be exported.
+//
+//	NewIdentifierPreservingUnderscore("_internalValue").Exported() -> "InternalValue"
+func (i Identifier) Exported() string {
+	return guardDigit(strings.TrimPrefix(i.Pascal, i.casing.prefix))
+}
+
+// IsExported returns whether the Pascal form of the Identifier is
+// an exported Go identifier, i.e. it begins with an uppercase letter.
+// This is not the case if it begins with a digit, a preserved
+// underscore, or a letter without case (e.g. "日本").
+func (i Identifier) IsExported() bool {
+	r, _ := utf8.DecodeRuneInString(i.Pascal)
+	return unicode.IsUpper(r)
+}
+
+// Unexported returns the unexported (i.e. camel case) form of the
+// Identifier. If the Identifier begins with a digit, it is
+// prefixed with an underscore so that it is a legal Go identifier.
+func (i Identifier) Unexported() string {
+	return guardDigit(i.Camel)
+}
+
+// WithSetter returns the name of an exported setter for the
+// Identifier in the functional options or fluent builder style,
+// i.e. "With" followed by its Pascal form. The corresponding field
+// and getter are named by the Camel and Pascal forms, respectively.
+// A preserved leading underscore is never included.
+//
+//	NewIdentifier("user_id").WithSetter() -> "WithUserID"
+func (i Identifier) WithSetter() string {
+	return "With" + strings.TrimPrefix(i.Pascal, i.casing.prefix)
+}
+
+// TestName returns the name of a Go test function for the type
+// named by the Identifier and the given method, in the conventional
+// TestType_Method form. The method is parsed and cased in the same
+// way as the Identifier, and is omitted if it has no words.
+//
+//	NewIdentifier("UserService").TestName("create") -> "TestUserService_Create"
+//	NewIdentifier("http_client").TestName("getURL") -> "TestHTTPClient_GetURL"
+//	NewIdentifier("UserService").TestName("")       -> "TestUserService"
+func (i Identifier) TestName(method string) string {
+	name := "Test" + pascal(i.Words, i.casing)
+	if m := pascal(parse(method), i.casing); m != "" {
+		name += "_" + m
+	}
+	return name
+}
+
+// SafeCamel returns the camel case form of the Identifier, with
+// an underscore appended if it is a Go keyword.
+//
+//	NewIdentifier("type").SafeCamel() -> "type_"
+func (i Identifier) SafeCamel() string {
+	return guardKeyword(i.Camel)
+}
+
+// SafePackage returns the package form of the Identifier, with
+// an underscore appended if it is a Go keyword.
+func (i Identifier) SafePackage() string {
+	return guardKeyword(i.Package)
+}
+
+// SafePascal returns the pascal case form of the Identifier, with
+// an underscore appended if it is a Go keyword.
+func (i Identifier) SafePascal() string {
+	return guardKeyword(i.Pascal)
+}
+
+// SafeSnake returns the snake case form of the Identifier, with
+// an underscore appended if it is a Go keyword.
+func (i Identifier) SafeSnake() string {
+	return guardKeyword(i.Snake)
+}
+
+// guardKeyword appends an underscore to the given string if it
+// is a Go keyword.
+func guardKeyword(s string) string {
+	if IsKeyword(s) {
+		return s + "_"
+	}
+	return s
+}
+
+// guardDigit prefixes the given string with an underscore if it
+// begins with a digit.
+func guardDigit(s string) string {
+	for _, r := range s {
+		if unicode.IsDigit(r) {
+			return "_" + s
+		}
+		break
+	}
+	return s
+}
+
+// ParseWords splits the given string into the lowercase words
+// used to construct an Identifier, using the same rules as
+// NewIdentifier.
+//
+//	ParseWords("foo.bar-baz_qux") -> [foo bar baz qux]
+//	ParseWords("HTTPServer")      -> [http server]
+func ParseWords(s string) []string {
+	return parse(s)
+}
+
+// SplitPreservingCase is like ParseWords, but each word keeps the
+// case it was written in.
+//
+//	SplitPreservingCase("HTTPServer")   -> [HTTP Server]
+//	SplitPreservingCase("Http_server")  -> [Http server]
+//	SplitPreservingCase("parseURLPath") -> [parse URL Path]
+func SplitPreservingCase(s string) []string {
+	return (&identParser{preserve: true}).parse(s)
+}
+
+// StrictParseWords is like ParseWords, but every delimiter is
+// significant: consecutive delimiters, as well as a leading or
+// trailing delimiter, produce an empty word. This lets the
+// original delimiters be recovered from the words.
+//
+//	StrictParseWords("foo_bar")  -> [foo bar]
+//	StrictParseWords("foo__bar") -> [foo  bar]
+//	StrictParseWords("_foo_")    -> [ foo ]
+func StrictParseWords(s string) []string {
+	return (&identParser{strict: true}).parse(s)
+}
+
+// replace returns the given words, with each word that matches a
+// key in the replacements replaced by the words of its value.
+func replace(words []string, replacements map[string]string) []string {
+	if len(replacements) == 0 {
+		return words
+	}
+	lower := make(map[string]string, len(replacements))
+	for word, replacement := range replacements {
+		lower[strings.ToLower(word)] = replacement
+	}
+	replaced := make([]string, 0, len(words))
+	for _, word := range words {
+		if replacement, ok := lower[word]; ok {
+			replaced = append(replaced, parse(replacement)...)
+			continue
+		}
+		replaced = append(replaced, word)
+	}
+	return replaced
+}
+
 // identParser manages state for parsing an identifier.
 type identParser struct {
-	word  strings.Builder
-	words []string
+	runes      []rune
+	word       []byte
+	words      []string
+	literals   [][]rune
+	boundaries map[rune]bool // if non-nil, the only punctuation delimiters
+	numbers    bool          // a digit that follows a letter begins a word
+	strict     bool          // keep empty words between delimiters
+	preserve   bool          // keep the original case of each rune
+	delimited  bool          // the last rune was a delimiter
+}
+
+// _parsers pools the identParsers used by NewIdentifierWithOptions,
+// so that their buffers are reused between Identifiers.
+var _parsers = sync.Pool{
+	New: func() interface{} {
+		return new(identParser)
+	},
+}
+
+// reset discards the state of the previous parse, but retains the
+// underlying buffers for reuse.
+func (p *identParser) reset() {
+	p.runes = p.runes[:0]
+	p.word = p.word[:0]
+	p.words = p.words[:0]
+	p.delimited = false
 }
 
 // shift adds the current word to the rolling set of words.
 // This is a no-op if the current word is empty.
 func (p *identParser) shift() {
-	if p.word.Len() > 0 {
-		p.words = append(p.words, p.word.String())
-		p.word.Reset()
+	if len(p.word) > 0 {
+		p.words = append(p.words, string(p.word))
+		p.word = p.word[:0]
+	}
+}
+
+// lower returns the lowercase form of the given rune, unless
+// the parser preserves the original case.
+func (p *identParser) lower(r rune) rune {
+	if p.preserve {
+		return r
 	}
+	return unicode.ToLower(r)
+}
+
+// delimit ends the current word at a delimiter. Unlike shift,
+// the word is added even if it is empty.
+func (p *identParser) delimit() {
+	p.words = append(p.words, string(p.word))
+	p.word = p.word[:0]
+	p.delimited = true
 }
 
 // write adds the given rune to the current word.
 func (p *identParser) write(r rune) {
-	p.word.WriteRune(r)
+	p.word = utf8.AppendRune(p.word, r)
+	p.delimited = false
 }
 
 // parse the given string into a slice of words.
+//
+// A run of consecutive uppercase letters is treated as a single
+// word, except that the final uppercase letter of the run begins
+// a new word when it is followed by a lowercase letter. A letter
+// that follows a digit also begins a new word, but a digit that
+// follows a letter does not. As a special case, a version marker
+// (a 'v' followed by a digit) that follows a run of uppercase
+// letters begins a new word. Apostrophes between two letters are
+// dropped rather than treated as a boundary:
+//
+//	HTTPServer     -> [http server]
+//	parseURLPath   -> [parse url path]
+//	base64encode   -> [base64 encode]
+//	HTTP2          -> [http2]
+//	HTTP2Server    -> [http2 server]
+//	H2C            -> [h2 c]
+//	IPv4           -> [ip v4]
+//	user's account -> [users account]
 func parse(s string) []string {
+	return new(identParser).parse(s)
+}
+
+// parse the given string into a slice of words. For details,
+// see the parse function.
+func (p *identParser) parse(s string) []string {
 	s = strings.TrimSpace(s)
 	if len(s) == 0 {
 		return nil
 	}
-	p := new(identParser)
+	p.runes = p.runes[:0]
 	for _, r := range s {
-		if isUpper(r) {
-			r = unicode.ToLower(r)
+		p.runes = append(p.runes, r)
+	}
+	runes := p.runes
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if n := p.literal(runes, i); n > 0 {
+			p.shift()
+			for _, l := range runes[i : i+n] {
+				p.write(p.lower(l))
+			}
 			p.shift()
+			i += n - 1
+			continue
+		}
+		if isUpper(r) {
+			if !inUpperRun(runes, i) {
+				p.shift()
+			}
+			r = p.lower(r)
 		}
 		if isLower(r) {
+			if i > 0 && unicode.IsLetter(r) && unicode.IsNumber(runes[i-1]) {
+				p.shift()
+			}
+			if p.numbers && i > 0 && unicode.IsNumber(r) && unicode.IsLetter(runes[i-1]) {
+				p.shift()
+			}
+			if isVersion(runes, i) && i > 1 && isUpper(runes[i-1]) && isUpper(runes[i-2]) {
+				p.shift()
+			}
 			p.write(r)
 			continue
 		}
+		if isApostrophe(r) && inWord(runes, i) {
+			continue
+		}
+		if p.boundaries != nil && !p.boundaries[r] && !unicode.IsSpace(r) {
+			continue
+		}
+		if p.strict {
+			p.delimit()
+			continue
+		}
 		p.shift()
 	}
+	if p.delimited {
+		p.delimit()
+	}
 	p.shift()
 	return p.words
 }
 
+// inUpperRun returns true if the uppercase rune at the given
+// index continues the run of uppercase runes that precedes it.
+func inUpperRun(runes []rune, i int) bool {
+	if i == 0 || !isUpper(runes[i-1]) {
+		return false
+	}
+	return i+1 == len(runes) || !unicode.IsLower(runes[i+1]) || isVersion(runes, i+1) || isPlural(runes, i+1)
+}
+
+// isPlural returns true if the rune at the given index is a lone
+// 's' that pluralizes the uppercase run before it, e.g. the "s" in
+// "userIDs".
+func isPlural(runes []rune, i int) bool {
+	return runes[i] == 's' && (i+1 == len(runes) || !unicode.IsLower(runes[i+1]))
+}
+
+// isVersion returns true if the rune at the given index begins
+// a version marker, e.g. the "v4" in "IPv4".
+func isVersion(runes []rune, i int) bool {
+	return runes[i] == 'v' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])
+}
+
+// inWord returns true if the rune at the given index is
+// surrounded by letters.
+func inWord(runes []rune, i int) bool {
+	return i > 0 && i+1 < len(runes) && unicode.IsLetter(runes[i-1]) && unicode.IsLetter(runes[i+1])
+}
+
+// isApostrophe returns true if the given rune represents an
+// apostrophe.
+func isApostrophe(r rune) bool {
+	return r == '\'' || r == '’'
+}
+
 // isUpper return strue if the given rune represents an
 // uppercase character.
 func isUpper(r rune) bool {
@@ -107,19 +751,35 @@ func isLower(r rune) bool {
 	return unicode.IsLetter(r) || unicode.IsNumber(r)
 }
 
-// camel case variant of the identifier.
-func camel(words []string) string {
+// camel case variant of the identifier. The first word is
+// always lowercase, even if it is an initialism, unless it is
+// a literal.
+func camel(words []string, c casing) string {
 	if len(words) == 0 {
 		return ""
 	}
 	var sb strings.Builder
-	sb.WriteString(words[0])
+	if literal, ok := c.literals[words[0]]; ok {
+		sb.WriteString(literal)
+	} else {
+		sb.WriteString(words[0])
+	}
 	for i := 1; i < len(words); i++ {
-		sb.WriteString(title(words[i]))
+		sb.WriteString(c.capitalize(words[i]))
 	}
 	return sb.String()
 }
 
+// constant is the screaming snake case variant of the identifier.
+func constant(words []string) string {
+	return strings.ToUpper(snake(words))
+}
+
+// dot case variant of the identifier.
+func dot(words []string) string {
+	return strings.Join(words, ".")
+}
+
 // kebab case variant of the identifier.
 func kebab(words []string) string {
 	return strings.Join(words, "-")
@@ -136,13 +796,13 @@ func packge(words []string) string {
 }
 
 // pascal case variant of the identifier.
-func pascal(words []string) string {
+func pascal(words []string, c casing) string {
 	if len(words) == 0 {
 		return ""
 	}
 	var sb strings.Builder
 	for _, word := range words {
-		sb.WriteString(title(word))
+		sb.WriteString(c.capitalize(word))
 	}
 	return sb.String()
 }
@@ -152,6 +812,25 @@ func snake(words []string) string {
 	return strings.Join(words, "_")
 }
 
+// titleCase is the space-separated, title-cased variant of the
+// identifier, suitable for display.
+func titleCase(words []string, c casing) string {
+	titled := make([]string, len(words))
+	for i, word := range words {
+		titled[i] = c.capitalize(word)
+	}
+	return strings.Join(titled, " ")
+}
+
+// train case variant of the identifier.
+func train(words []string, c casing) string {
+	titled := make([]string, len(words))
+	for i, word := range words {
+		titled[i] = c.capitalize(word)
+	}
+	return strings.Join(titled, "-")
+}
+
 // title returns the title-equivalent representation of the
 // given string.
 func title(s string) string {
@@ -0,0 +1,508 @@
+package gospec
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestIdentifierSingular(t *testing.T) {
+	tests := []struct {
+		give string
+		want string
+	}{
+		{give: "users", want: "user"},
+		{give: "responses", want: "response"},
+		{give: "cases", want: "case"},
+		{give: "databases", want: "database"},
+		{give: "licenses", want: "license"},
+		{give: "purposes", want: "purpose"},
+		{give: "buses", want: "bus"},
+		{give: "boxes", want: "box"},
+		{give: "classes", want: "class"},
+		{give: "churches", want: "church"},
+		{give: "dishes", want: "dish"},
+		{give: "categories", want: "category"},
+		{give: "people", want: "person"},
+		{give: "metadata", want: "metadata"},
+		{give: "address", want: "address"},
+		{give: "status", want: "status"},
+		{give: "bus", want: "bus"},
+		{give: "alias", want: "alias"},
+		{give: "axis", want: "axis"},
+		{give: "statuses", want: "status"},
+		{give: "bonus", want: "bonus"},
+		{give: "basis", want: "basis"},
+		{give: "user", want: "user"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.give, func(t *testing.T) {
+			id, err := NewIdentifier(tt.give)
+			if err != nil {
+				t.Fatalf("NewIdentifier(%q) error = %v", tt.give, err)
+			}
+			if got := id.Singular().Snake; got != tt.want {
+				t.Errorf("Singular() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIdentifierPlural(t *testing.T) {
+	tests := []struct {
+		give string
+		want string
+	}{
+		{give: "user", want: "users"},
+		{give: "users", want: "users"},
+		{give: "category", want: "categories"},
+		{give: "categories", want: "categories"},
+		{give: "key", want: "keys"},
+		{give: "box", want: "boxes"},
+		{give: "boxes", want: "boxes"},
+		{give: "class", want: "classes"},
+		{give: "church", want: "churches"},
+		{give: "bus", want: "buses"},
+		{give: "buses", want: "buses"},
+		{give: "status", want: "statuses"},
+		{give: "person", want: "people"},
+		{give: "people", want: "people"},
+		{give: "metadata", want: "metadata"},
+		{give: "userAccount", want: "user_accounts"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.give, func(t *testing.T) {
+			id, err := NewIdentifier(tt.give)
+			if err != nil {
+				t.Fatalf("NewIdentifier(%q) error = %v", tt.give, err)
+			}
+			if got := id.Plural().Snake; got != tt.want {
+				t.Errorf("Plural() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewIdentifierUpperRuns(t *testing.T) {
+	tests := []struct {
+		give       string
+		wantWords  []string
+		wantPascal string
+		wantSnake  string
+	}{
+		{
+			give:       "userIDs",
+			wantWords:  []string{"user", "ids"},
+			wantPascal: "UserIDs",
+			wantSnake:  "user_ids",
+		},
+		{
+			give:       "JSONData",
+			wantWords:  []string{"json", "data"},
+			wantPascal: "JSONData",
+			wantSnake:  "json_data",
+		},
+		{
+			give:       "parseURLPath",
+			wantWords:  []string{"parse", "url", "path"},
+			wantPascal: "ParseURLPath",
+			wantSnake:  "parse_url_path",
+		},
+		{
+			give:       "ID",
+			wantWords:  []string{"id"},
+			wantPascal: "ID",
+			wantSnake:  "id",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.give, func(t *testing.T) {
+			id, err := NewIdentifier(tt.give)
+			if err != nil {
+				t.Fatalf("NewIdentifier(%q) error = %v", tt.give, err)
+			}
+			if !equalWords(id.Words, tt.wantWords) {
+				t.Errorf("Words = %q, want %q", id.Words, tt.wantWords)
+			}
+			if id.Pascal != tt.wantPascal {
+				t.Errorf("Pascal = %q, want %q", id.Pascal, tt.wantPascal)
+			}
+			if id.Snake != tt.wantSnake {
+				t.Errorf("Snake = %q, want %q", id.Snake, tt.wantSnake)
+			}
+		})
+	}
+}
+
+func TestNewIdentifierTrain(t *testing.T) {
+	tests := []struct {
+		give      string
+		wantTitle string
+		wantTrain string
+	}{
+		{give: "HTTPServer", wantTitle: "HTTP Server", wantTrain: "HTTP-Server"},
+		{give: "userId", wantTitle: "User ID", wantTrain: "User-ID"},
+		{give: "content_type", wantTitle: "Content Type", wantTrain: "Content-Type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.give, func(t *testing.T) {
+			id, err := NewIdentifier(tt.give)
+			if err != nil {
+				t.Fatalf("NewIdentifier(%q) error = %v", tt.give, err)
+			}
+			if id.Title != tt.wantTitle {
+				t.Errorf("Title = %q, want %q", id.Title, tt.wantTitle)
+			}
+			if id.Train != tt.wantTrain {
+				t.Errorf("Train = %q, want %q", id.Train, tt.wantTrain)
+			}
+		})
+	}
+}
+
+func ExampleIdentifier_Join() {
+	screaming := func(word string, _ int) string {
+		return strings.ToUpper(word)
+	}
+	id, err := NewIdentifier("userAccount")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(id.Join(screaming, "_"))
+	fmt.Println(id.Constant)
+	// Output:
+	// USER_ACCOUNT
+	// USER_ACCOUNT
+}
+
+func TestIdentifierJoin(t *testing.T) {
+	screaming := func(word string, _ int) string {
+		return strings.ToUpper(word)
+	}
+	for _, give := range []string{"userAccount", "HTTPServer", "user_ids", "api v2"} {
+		t.Run(give, func(t *testing.T) {
+			id, err := NewIdentifier(give)
+			if err != nil {
+				t.Fatalf("NewIdentifier(%q) error = %v", give, err)
+			}
+			if got := id.Join(screaming, "_"); got != id.Constant {
+				t.Errorf("Join() = %q, want Constant %q", got, id.Constant)
+			}
+		})
+	}
+}
+
+func TestIdentifierExported(t *testing.T) {
+	tests := []struct {
+		desc           string
+		give           func() (*Identifier, error)
+		wantExported   string
+		wantUnexported string
+	}{
+		{
+			desc:           "identifier",
+			give:           func() (*Identifier, error) { return NewIdentifier("user_id") },
+			wantExported:   "UserID",
+			wantUnexported: "userID",
+		},
+		{
+			desc:           "leading digit",
+			give:           func() (*Identifier, error) { return NewIdentifier("2020") },
+			wantExported:   "_2020",
+			wantUnexported: "_2020",
+		},
+		{
+			desc:           "preserved underscore",
+			give:           func() (*Identifier, error) { return NewIdentifierPreservingUnderscore("_internalValue") },
+			wantExported:   "InternalValue",
+			wantUnexported: "_internalValue",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			id, err := tt.give()
+			if err != nil {
+				t.Fatalf("NewIdentifier() error = %v", err)
+			}
+			if got := id.Exported(); got != tt.wantExported {
+				t.Errorf("Exported() = %q, want %q", got, tt.wantExported)
+			}
+			if got := id.Unexported(); got != tt.wantUnexported {
+				t.Errorf("Unexported() = %q, want %q", got, tt.wantUnexported)
+			}
+		})
+	}
+}
+
+func TestFuncMap(t *testing.T) {
+	tests := []struct {
+		give string
+		want string
+	}{
+		{give: "{{camel .}}", want: "userID"},
+		{give: "{{pascal .}}", want: "UserID"},
+		{give: "{{snake .}}", want: "user_id"},
+		{give: "{{kebab .}}", want: "user-id"},
+		{give: "{{natural .}}", want: "user id"},
+		{give: "{{package .}}", want: "userid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.give, func(t *testing.T) {
+			tmpl, err := template.New("").Funcs(FuncMap()).Parse(tt.give)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.give, err)
+			}
+			var sb strings.Builder
+			if err := tmpl.Execute(&sb, "userId"); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if got := sb.String(); got != tt.want {
+				t.Errorf("Execute() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewIdentifierSnake(t *testing.T) {
+	tests := []struct {
+		give string
+		want string
+	}{
+		{give: "user's account", want: "users_account"},
+		{give: "it's", want: "its"},
+		{give: "it’s here", want: "its_here"},
+		{give: "'quoted'", want: "quoted"},
+		{give: "HTTP2Server", want: "http2_server"},
+		{give: "HTTP2", want: "http2"},
+		{give: "H2C", want: "h2_c"},
+		{give: "IPv4", want: "ip_v4"},
+		{give: "IPv6Address", want: "ip_v6_address"},
+		{give: "APIv2", want: "api_v2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.give, func(t *testing.T) {
+			id, err := NewIdentifier(tt.give)
+			if err != nil {
+				t.Fatalf("NewIdentifier(%q) error = %v", tt.give, err)
+			}
+			if id.Snake != tt.want {
+				t.Errorf("Snake = %q, want %q", id.Snake, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewIdentifierNotGoIdentifier(t *testing.T) {
+	tests := []struct {
+		give string
+		want string
+	}{
+		{give: "user-id", want: "UserID"},
+		{give: "user id", want: "UserID"},
+		{give: "3dModel", want: "3DModel"},
+		{give: "2020", want: "2020"},
+		{give: "foo.bar", want: "FooBar"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.give, func(t *testing.T) {
+			id, err := NewIdentifier(tt.give)
+			if err != nil {
+				t.Fatalf("NewIdentifier(%q) error = %v", tt.give, err)
+			}
+			if id.Pascal != tt.want {
+				t.Errorf("Pascal = %q, want %q", id.Pascal, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewIdentifierEmpty(t *testing.T) {
+	tests := []struct {
+		desc string
+		give string
+	}{
+		{desc: "empty", give: ""},
+		{desc: "whitespace", give: " \t\n"},
+		{desc: "punctuation", give: "---"},
+		{desc: "underscore", give: "_"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if id, err := NewIdentifier(tt.give); err == nil {
+				t.Errorf("NewIdentifier(%q) = %+v, want error", tt.give, id)
+			}
+		})
+	}
+	var zero Identifier
+	for style := range _styleNames {
+		if got := zero.In(Style(style)); got != "" {
+			t.Errorf("zero value %v form = %q, want empty", Style(style), got)
+		}
+	}
+}
+
+func TestNewIdentifierWithLiterals(t *testing.T) {
+	literals := []string{"GraphQL", "OAuth2"}
+	tests := []struct {
+		give       string
+		wantPascal string
+		wantSnake  string
+	}{
+		{give: "oauth2Provider", wantPascal: "OAuth2Provider", wantSnake: "oauth2_provider"},
+		{give: "newOAuth2Client", wantPascal: "NewOAuth2Client", wantSnake: "new_oauth2_client"},
+		{give: "graphqlSchema", wantPascal: "GraphQLSchema", wantSnake: "graphql_schema"},
+		{give: "my_graphql_api", wantPascal: "MyGraphQLAPI", wantSnake: "my_graphql_api"},
+		{give: "OAuth2GraphQLToken", wantPascal: "OAuth2GraphQLToken", wantSnake: "oauth2_graphql_token"},
+		{give: "graphqlite", wantPascal: "Graphqlite", wantSnake: "graphqlite"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.give, func(t *testing.T) {
+			id, err := NewIdentifierWithLiterals(tt.give, literals)
+			if err != nil {
+				t.Fatalf("NewIdentifierWithLiterals(%q) error = %v", tt.give, err)
+			}
+			if id.Pascal != tt.wantPascal {
+				t.Errorf("Pascal = %q, want %q", id.Pascal, tt.wantPascal)
+			}
+			if id.Snake != tt.wantSnake {
+				t.Errorf("Snake = %q, want %q", id.Snake, tt.wantSnake)
+			}
+		})
+	}
+}
+
+func TestToIdentifier(t *testing.T) {
+	tests := []struct {
+		give string
+		want string
+	}{
+		{give: "user😀name", want: "username"},
+		{give: "café", want: "café"},
+		{give: "x٣", want: "x٣"},
+		{give: "x²", want: "x"},
+		{give: "😀", want: "_"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.give, func(t *testing.T) {
+			got := ToIdentifier(tt.give)
+			if got != tt.want {
+				t.Errorf("ToIdentifier(%q) = %q, want %q", tt.give, got, tt.want)
+			}
+			if err := ValidateIdentifier(got); err != nil {
+				t.Errorf("ValidateIdentifier(%q) error = %v", got, err)
+			}
+		})
+	}
+}
+
+func TestIdentifierJSON(t *testing.T) {
+	type record struct {
+		Name Identifier `json:"name"`
+	}
+	tests := []struct {
+		desc string
+		give string
+		want string
+	}{
+		{desc: "identifier", give: "userID", want: "user_id"},
+		{desc: "zero value", give: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var in record
+			if tt.give != "" {
+				id, err := NewIdentifier(tt.give)
+				if err != nil {
+					t.Fatalf("NewIdentifier(%q) error = %v", tt.give, err)
+				}
+				in.Name = *id
+			}
+			data, err := json.Marshal(in)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			var out record
+			if err := json.Unmarshal(data, &out); err != nil {
+				t.Fatalf("Unmarshal(%s) error = %v", data, err)
+			}
+			if !out.Name.Equal(in.Name) || out.Name.Source != tt.give || out.Name.Snake != tt.want {
+				t.Errorf("Unmarshal(%s) = %+v, want %+v", data, out.Name, in.Name)
+			}
+		})
+	}
+}
+
+func TestIdentifierUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		desc    string
+		give    string
+		want    string
+		wantErr bool
+	}{
+		{desc: "string", give: `"userID"`, want: "user_id"},
+		{desc: "null", give: `null`, want: "existing"},
+		{desc: "empty string", give: `""`, want: ""},
+		{desc: "punctuation", give: `"---"`, wantErr: true},
+		{desc: "number", give: `1`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			id, err := NewIdentifier("existing")
+			if err != nil {
+				t.Fatalf("NewIdentifier() error = %v", err)
+			}
+			err = json.Unmarshal([]byte(tt.give), id)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Unmarshal(%s) = %+v, want error", tt.give, id)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unmarshal(%s) error = %v", tt.give, err)
+			}
+			if id.Snake != tt.want {
+				t.Errorf("Unmarshal(%s) Snake = %q, want %q", tt.give, id.Snake, tt.want)
+			}
+		})
+	}
+}
+
+func BenchmarkNewIdentifier(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := NewIdentifier("parseHTTPServerURLPath"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkTokenizerWords(b *testing.B) {
+	b.ReportAllocs()
+	var tokenizer Tokenizer
+	for i := 0; i < b.N; i++ {
+		tokenizer.Words("parseHTTPServerURLPath")
+	}
+}
+
+func BenchmarkNewIdentifierCached(b *testing.B) {
+	names := []string{"userID", "parseHTTPServerURLPath", "created_at", "OAuth2Provider"}
+	b.Run("uncached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := NewIdentifier(names[i%len(names)]); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := NewIdentifierCached(names[i%len(names)]); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
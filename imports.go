@@ -1,139 +1,946 @@
 package gospec
 
 import (
-	"bytes"
+	"errors"
 	"fmt"
-	"go/format"
-	"go/parser"
+	"go/ast"
+	"go/scanner"
 	"go/token"
+	"io"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 
 	"golang.org/x/tools/go/ast/astutil"
 )
 
-// invalidIdentifier matches invalid identifier characters
-// according to the Go language spec.
-var invalidIdentifierChar = regexp.MustCompile("[^[:digit:][:alpha:]_]")
+// majorVersion matches a module major version suffix path element,
+// such as the "v2" in "github.com/foo/bar/v2".
+var majorVersion = regexp.MustCompile("^v[1-9][0-9]*$")
+
+// gopkgVersion matches a gopkg.in-style version suffix, such as
+// the ".v2" in "gopkg.in/yaml.v2".
+var gopkgVersion = regexp.MustCompile(`\.v[0-9]+$`)
+
+// moduleVersion matches a module version query suffix on the
+// final path element, such as the "@v1.2.3" in
+// "github.com/foo/bar@v1.2.3".
+var moduleVersion = regexp.MustCompile(`@[^/]*$`)
 
 // Imports maps a set of import paths to unique aliases.
-type Imports map[string]string
+// The zero value is ready to use. Imports is not safe for
+// concurrent use; use SyncImports if the same set of imports
+// is shared between goroutines.
+type Imports struct {
+	// AllowPredeclared permits aliases that shadow one of the Go
+	// predeclared identifiers, such as "error" or "string".
+	AllowPredeclared bool
+
+	// FoldCase registers every path in lowercase, so that paths
+	// which differ only by case share a single alias. Module paths
+	// are case-sensitive, so this is only appropriate when the
+	// paths are known to be case-insensitive.
+	FoldCase bool
+
+	// KnownAliases maps import paths to the alias that Add should
+	// prefer for them, e.g. the conventional "metav1" for
+	// "k8s.io/apimachinery/pkg/apis/meta/v1". If the known alias is
+	// not available, the alias is chosen as usual.
+	KnownAliases map[string]string
+
+	// Module is the path of the module that the imports belong
+	// to, if any. Paths within the module are never considered
+	// part of the standard library, even if their first element
+	// does not contain a dot.
+	Module string
 
-// Add adds the path to the imports map, initially using the
-// base directory as the package alias. If this alias is
+	// Package is the name of the package that the imports belong
+	// to, if any. It is never used as an alias, so that an import
+	// isn't confused with the enclosing package.
+	Package string
+
+	// Resolver, if non-nil, reports the name declared by the package
+	// with the given import path, e.g. by loading the package. If the
+	// name is found, it is used as the base of the alias in place of
+	// the final path element, which may differ from the package name.
+	Resolver func(path string) (name string, ok bool)
+
+	aliases  map[string]string   // path -> alias
+	paths    map[string]string   // alias -> path
+	explicit map[string]struct{} // paths added with AddWithAlias
+	std      map[string]struct{} // paths added with AddStd
+	reserved map[string]struct{} // names added with Reserve
+}
+
+// NewImports returns an empty imports map. If the name of the
+// package that the imports belong to is given, it is set as the
+// Package, so it's never used as an alias. Only the first name is
+// used.
+//
+//	imports := NewImports("json")
+//	imports.Add("encoding/json") -> "encodingjson"
+func NewImports(selfPackage ...string) *Imports {
+	imp := &Imports{
+		aliases: make(map[string]string),
+		paths:   make(map[string]string),
+	}
+	if len(selfPackage) > 0 {
+		imp.Package = selfPackage[0]
+	}
+	return imp
+}
+
+// Add adds the path to the imports map, using its KnownAliases
+// entry if it is available. Otherwise, the base directory is
+// initially used as the package alias. If this alias is
 // already in use, we continue to prepend the remaining filepath
 // elements until we have receive a unique alias. If all of the
-// path elements are exhausted, an 'x' is continually used
-// until we create a unique alias.
+// path elements are exhausted, an incrementing integer is
+// appended to the base directory until we create a unique alias.
+// Version suffixes, such as "/v2" and ".v2", are never used in
+// the alias. A module version query suffix, such as "@v1.2.3",
+// is removed from the path before it is registered, so the path
+// is always stored (and returned by Paths) without it.
 //
-//   imports := NewImports("json")
-//   imports.Add("encoding/json") -> "encodingjson"
-//   imports.Add("encodingjson")  -> "xencodingjson"
-func (imp Imports) Add(path string) string {
+//	imports.Add("encoding/json")       -> "json"
+//	imports.Add("github.com/foo/json") -> "foojson"
+//	imports.Add("foojson")             -> "foojson2"
+func (imp *Imports) Add(path string) string {
+	path = imp.normalize(path)
 	if path == "" || path == "." || path == "/" {
 		return ""
 	}
-	if alias, ok := imp[path]; ok {
+	if alias, ok := imp.aliases[path]; ok {
 		return alias
 	}
-	var (
-		alias string
-		elems = strings.Split(path, "/")
-	)
+	if alias, ok := imp.KnownAliases[path]; ok && imp.isValid(alias) {
+		imp.set(path, alias)
+		return alias
+	}
+	elems := aliasElems(path)
+	if name, ok := imp.resolve(path); ok {
+		if len(elems) == 0 {
+			elems = []string{name}
+		}
+		elems[len(elems)-1] = name
+	}
 	for i := 1; i <= len(elems); i++ {
-		alias = newAlias(elems[len(elems)-i:])
+		alias := strings.Join(elems[len(elems)-i:], "")
+		if imp.isValid(alias) {
+			imp.set(path, alias)
+			return alias
+		}
+	}
+	// The base alias must begin with a letter, otherwise no
+	// number of suffixes would ever make it valid.
+	base := "x"
+	if len(elems) > 0 {
+		base = elems[len(elems)-1]
+		if r, _ := utf8.DecodeRuneInString(base); !unicode.IsLetter(r) {
+			base = "x" + base
+		}
+	}
+	if imp.isValid(base) {
+		imp.set(path, base)
+		return base
+	}
+	for n := 2; ; n++ {
+		alias := base + strconv.Itoa(n)
 		if imp.isValid(alias) {
-			imp[path] = alias
+			imp.set(path, alias)
 			return alias
 		}
 	}
-	for !imp.isValid(alias) {
-		alias = fmt.Sprintf("x%s", alias)
+}
+
+// AddChecked is like Add, but returns an error rather than the
+// empty alias if the path does not name an importable package,
+// i.e. it is empty, ".", or "/".
+func (imp *Imports) AddChecked(path string) (string, error) {
+	if p := imp.normalize(path); p == "" || p == "." || p == "/" {
+		return "", fmt.Errorf("%q is not a valid import path", path)
+	}
+	return imp.Add(path), nil
+}
+
+// AddAll adds each of the given paths with Add, and returns their
+// aliases in the same order. Paths are added in order, so earlier
+// paths take precedence for a contested alias.
+//
+//	imports.AddAll("text/template", "html/template") -> ["template", "htmltemplate"]
+func (imp *Imports) AddAll(paths ...string) []string {
+	aliases := make([]string, len(paths))
+	for i, path := range paths {
+		aliases[i] = imp.Add(path)
+	}
+	return aliases
+}
+
+// AddWithAlias adds the path to the imports map using the given
+// alias. If the path is already present, its existing alias is
+// returned instead. An error is returned if the alias is not a
+// valid identifier, is a Go keyword, is the Package name or a
+// reserved name, or is already in use by another path. The "."
+// alias registers a dot import, and may be used by any number of
+// paths.
+func (imp *Imports) AddWithAlias(path, alias string) (string, error) {
+	path = imp.normalize(path)
+	if path == "" || path == "." || path == "/" {
+		return "", fmt.Errorf("%q is not a valid import path", path)
+	}
+	if existing, ok := imp.aliases[path]; ok {
+		return existing, nil
 	}
-	imp[path] = alias
+	if p, ok := imp.paths[alias]; ok {
+		return "", fmt.Errorf("alias %q is already in use by %q", alias, p)
+	}
+	if alias != "." && !imp.isValid(alias) {
+		return "", fmt.Errorf("%q is not a valid import alias", alias)
+	}
+	imp.set(path, alias)
+	if imp.explicit == nil {
+		imp.explicit = make(map[string]struct{})
+	}
+	imp.explicit[path] = struct{}{}
+	return alias, nil
+}
+
+// AddStd adds the standard library path to the imports map,
+// choosing its alias with the same rules as Add, e.g.
+//
+//	imports.AddStd("text/template") -> "template"
+//	imports.AddStd("html/template") -> "htmltemplate"
+//
+// The path is always grouped with the standard library by
+// Partition and Render, regardless of its elements or the Module.
+func (imp *Imports) AddStd(path string) string {
+	path = imp.normalize(path)
+	alias := imp.Add(path)
+	if alias == "" {
+		return ""
+	}
+	if imp.std == nil {
+		imp.std = make(map[string]struct{})
+	}
+	imp.std[path] = struct{}{}
 	return alias
 }
 
+// AddHint adds the path to the imports map, preferring the given
+// alias hint. If the hint is invalid or already in use, the alias
+// is chosen by Add instead. The alias actually assigned is returned.
+func (imp *Imports) AddHint(path, hint string) string {
+	path = imp.normalize(path)
+	if path == "" || path == "." || path == "/" {
+		return ""
+	}
+	if alias, ok := imp.aliases[path]; ok {
+		return alias
+	}
+	if imp.isValid(hint) {
+		imp.set(path, hint)
+		return hint
+	}
+	return imp.Add(path)
+}
+
+// set registers the path under the given alias. Dot imports
+// are not unique, so they are omitted from the reverse index.
+func (imp *Imports) set(path, alias string) {
+	if imp.aliases == nil {
+		imp.aliases = make(map[string]string)
+		imp.paths = make(map[string]string)
+	}
+	imp.aliases[path] = alias
+	if alias != "." {
+		imp.paths[alias] = path
+	}
+}
+
+// Reserve prevents the given names from being used as an alias,
+// e.g. the exported names of a dot-imported package, or the names
+// declared by the generated code. Names that are already in use
+// as an alias are unaffected.
+func (imp *Imports) Reserve(names ...string) {
+	if imp.reserved == nil {
+		imp.reserved = make(map[string]struct{}, len(names))
+	}
+	for _, name := range names {
+		imp.reserved[name] = struct{}{}
+	}
+}
+
+// Qualify returns the given name qualified by the alias of the
+// given path, adding the path if it is not already registered.
+// The name is returned unqualified for the current package (i.e.
+// the empty path) and for dot imports.
+//
+//	imports.Qualify("encoding/json", "Marshal") -> "json.Marshal"
+//	imports.Qualify("", "Marshal")              -> "Marshal"
+func (imp *Imports) Qualify(path, name string) string {
+	alias := imp.Add(path)
+	if alias == "" || alias == "." {
+		return name
+	}
+	return alias + "." + name
+}
+
+// PackageName returns the conventional package name for the given
+// import path. This is the final element of the path, with any
+// version suffix and invalid identifier characters removed. The
+// version suffix is removed first, so both apply together.
+//
+//	PackageName("github.com/foo/go-bar")    -> "gobar"
+//	PackageName("github.com/foo/bar/v2")    -> "bar"
+//	PackageName("github.com/foo/go-bar/v2") -> "gobar"
+//	PackageName("gopkg.in/yaml.v2")         -> "yaml"
+func PackageName(path string) string {
+	elems := aliasElems(path)
+	if len(elems) == 0 {
+		return ""
+	}
+	return elems[len(elems)-1]
+}
+
+// cleanPath returns the given import path with any backslashes
+// (e.g. from a Windows file path) replaced by slashes, and without
+// its module version query suffix or trailing slashes, if any.
+// Paths are always registered in this form.
+//
+//	github.com/foo/bar@v1.2.3 -> github.com/foo/bar
+//	github.com/foo/@v1.2.3    -> github.com/foo
+//	github.com\foo\bar        -> github.com/foo/bar
+func cleanPath(path string) string {
+	path = strings.ReplaceAll(path, `\`, "/")
+	return strings.TrimRight(moduleVersion.ReplaceAllString(path, ""), "/")
+}
+
+// normalize returns the form of the given import path that is
+// used to register it.
+func (imp *Imports) normalize(path string) string {
+	path = cleanPath(path)
+	if imp.FoldCase {
+		path = strings.ToLower(path)
+	}
+	return path
+}
+
+// resolve returns the package name reported by the Resolver for
+// the given import path, with any invalid identifier characters
+// removed.
+func (imp *Imports) resolve(path string) (string, bool) {
+	if imp.Resolver == nil {
+		return "", false
+	}
+	name, ok := imp.Resolver(path)
+	if name = StripInvalidIdentifierChars(name); !ok || name == "" {
+		return "", false
+	}
+	return name, true
+}
+
+// packageName returns the name of the package with the given
+// import path, as reported by the Resolver if possible, and by
+// PackageName otherwise.
+func (imp *Imports) packageName(path string) string {
+	if name, ok := imp.resolve(path); ok {
+		return name
+	}
+	return PackageName(path)
+}
+
+// implied reports whether an unnamed import of the given path is
+// certain to be referred to by the given alias, so that the alias
+// can be omitted from its import spec. The alias is only implied if
+// it's confirmed by the Resolver, or is the final element of the
+// path; the PackageName of a path that doesn't end with its package
+// name (e.g. "core" for "k8s.io/api/core/v1") is only a guess.
+func (imp *Imports) implied(path, alias string) bool {
+	if name, ok := imp.resolve(path); ok {
+		return alias == name
+	}
+	return alias == path[strings.LastIndex(path, "/")+1:]
+}
+
+// pathElems splits the given import path into its elements,
+// stripping any version suffix from the final element(s).
+//
+//	github.com/foo/bar/v2 -> [github.com foo bar]
+//	gopkg.in/yaml.v2      -> [gopkg.in yaml]
+func pathElems(path string) []string {
+	elems := strings.Split(cleanPath(path), "/")
+	if n := len(elems); n > 1 && majorVersion.MatchString(elems[n-1]) {
+		elems = elems[:n-1]
+	}
+	n := len(elems)
+	elems[n-1] = gopkgVersion.ReplaceAllString(elems[n-1], "")
+	return elems
+}
+
+// aliasElems returns the elements of the given import path that
+// can contribute to an alias, with all invalid identifier characters
+// removed. Elements that are left empty, such as "...", are skipped.
+//
+//	github.com/foo/.../go-bar -> [githubcom foo gobar]
+func aliasElems(path string) []string {
+	var elems []string
+	for _, elem := range pathElems(path) {
+		if alias := newAlias([]string{elem}); alias != "" {
+			elems = append(elems, alias)
+		}
+	}
+	return elems
+}
+
 // newAlias returns an alias for the given set of filepath elements.
 // We explicitly remove all characters that are not included in
 // the identifier grammar.
 // For details, see https://golang.org/ref/spec#Identifiers.
 func newAlias(elems []string) string {
-	alias := strings.Join(elems, "")
-	return invalidIdentifierChar.ReplaceAllString(alias, "")
+	return StripInvalidIdentifierChars(strings.Join(elems, ""))
 }
 
 // isValid determines whether the given alias is an invalid identifier,
-// a Go keyword, or already registered in the import map.
-func (imp Imports) isValid(alias string) bool {
-	if len(alias) == 0 || isKeyword(alias) {
+// a Go keyword, a predeclared identifier (unless AllowPredeclared is
+// set), the Package name, a reserved name, or already registered in
+// the import map. The reverse index makes the registration check
+// constant time.
+func (imp *Imports) isValid(alias string) bool {
+	if ValidateIdentifier(alias) != nil {
+		return false
+	}
+	if imp.Package != "" && alias == imp.Package {
+		return false
+	}
+	if _, ok := imp.reserved[alias]; ok {
+		return false
+	}
+	if !imp.AllowPredeclared && IsPredeclared(alias) {
 		return false
 	}
 	for _, r := range alias {
 		// We use a range loop here so that we guarantee that we
 		// select the first rune (and not arbitrary bytes).
 		// For details, see https://blog.golang.org/strings.
+		// Although legal, an alias must not begin with an
+		// underscore.
 		if !unicode.IsLetter(r) {
 			return false
 		}
 		break
 	}
-	for _, s := range imp {
-		if s == alias {
-			return false
+	_, ok := imp.paths[alias]
+	return !ok
+}
+
+// Has returns whether the given path is registered.
+func (imp *Imports) Has(path string) bool {
+	path = imp.normalize(path)
+	_, ok := imp.aliases[path]
+	return ok
+}
+
+// Remove removes the given path from the imports map. Its alias
+// is freed, and may be used by a subsequent call to Add.
+func (imp *Imports) Remove(path string) {
+	path = imp.normalize(path)
+	if alias, ok := imp.aliases[path]; ok {
+		delete(imp.aliases, path)
+		if alias != "." {
+			delete(imp.paths, alias)
 		}
+		delete(imp.explicit, path)
+		delete(imp.std, path)
 	}
-	return true
 }
 
-// isKeyword returns whether the given string is a Go keyword.
-func isKeyword(s string) bool {
-	_, ok := _keywords[s]
-	return ok
+// Clone returns a copy of the imports map. Changes to the
+// clone do not affect the original, and vice versa.
+func (imp *Imports) Clone() *Imports {
+	clone := &Imports{
+		AllowPredeclared: imp.AllowPredeclared,
+		FoldCase:         imp.FoldCase,
+		Module:           imp.Module,
+		Package:          imp.Package,
+		Resolver:         imp.Resolver,
+	}
+	for path, alias := range imp.KnownAliases {
+		if clone.KnownAliases == nil {
+			clone.KnownAliases = make(map[string]string, len(imp.KnownAliases))
+		}
+		clone.KnownAliases[path] = alias
+	}
+	for path, alias := range imp.aliases {
+		clone.set(path, alias)
+	}
+	for path := range imp.explicit {
+		if clone.explicit == nil {
+			clone.explicit = make(map[string]struct{})
+		}
+		clone.explicit[path] = struct{}{}
+	}
+	for path := range imp.std {
+		if clone.std == nil {
+			clone.std = make(map[string]struct{})
+		}
+		clone.std[path] = struct{}{}
+	}
+	for name := range imp.reserved {
+		clone.Reserve(name)
+	}
+	return clone
+}
+
+// Merge adds every path registered in other. Paths that are
+// already registered keep their existing alias. New paths that
+// other registered with AddWithAlias keep their alias if it is
+// still available; all other new paths are added with Add, so
+// alias collisions are resolved automatically.
+//
+// An error is returned, and no paths are added, if other
+// explicitly aliased a path that is already registered under
+// a different alias.
+func (imp *Imports) Merge(other *Imports) error {
+	paths := other.Paths()
+	for _, path := range paths {
+		if _, ok := other.explicit[path]; !ok {
+			continue
+		}
+		if alias, ok := imp.aliases[path]; ok && alias != other.aliases[path] {
+			return fmt.Errorf("import %q is aliased as both %q and %q", path, alias, other.aliases[path])
+		}
+	}
+	// Explicit aliases are registered first so that they
+	// aren't claimed by an automatically generated alias.
+	for _, path := range paths {
+		if _, ok := other.explicit[path]; ok && !imp.Has(path) {
+			// The alias may already be taken, in which
+			// case the path is added below.
+			_, _ = imp.AddWithAlias(path, other.aliases[path])
+		}
+	}
+	for _, path := range paths {
+		if _, ok := other.std[path]; ok {
+			imp.AddStd(path)
+			continue
+		}
+		imp.Add(path)
+	}
+	return nil
+}
+
+// Path returns the import path registered under the given alias.
+// The empty alias returned by Add for the current package is never
+// registered, so it is never found.
+func (imp *Imports) Path(alias string) (string, bool) {
+	if alias == "" {
+		return "", false
+	}
+	path, ok := imp.paths[alias]
+	return path, ok
+}
+
+// Paths returns the registered import paths in lexical order.
+func (imp *Imports) Paths() []string {
+	paths := make([]string, 0, len(imp.aliases))
+	for path := range imp.aliases {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Sorted returns the registered {path, alias} pairs, sorted
+// lexically by path.
+func (imp *Imports) Sorted() [][2]string {
+	paths := imp.Paths()
+	pairs := make([][2]string, len(paths))
+	for i, path := range paths {
+		pairs[i] = [2]string{path, imp.aliases[path]}
+	}
+	return pairs
+}
+
+// String returns a line for each registered path and its alias,
+// sorted lexically by path, for debugging.
+//
+//	encoding/json => json
+//	github.com/foo/json => foojson
+func (imp *Imports) String() string {
+	var sb strings.Builder
+	for i, pair := range imp.Sorted() {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(pair[0])
+		sb.WriteString(" => ")
+		sb.WriteString(pair[1])
+	}
+	return sb.String()
+}
+
+// Render returns a gofmt-style import declaration containing
+// every registered path. The standard library imports are grouped
+// before all other imports, and each group is sorted by path. An
+// alias is only omitted if it's the package name reported by the
+// Resolver, or the final element of its path.
+//
+//	import (
+//		"encoding/json"
+//
+//		grpcjson "github.com/grpc/json"
+//	)
+func (imp *Imports) Render() string {
+	if imp.Len() == 0 {
+		return ""
+	}
+	std, external := imp.Partition()
+
+	var sb strings.Builder
+	sb.WriteString("import (\n")
+	imp.render(&sb, std)
+	if len(std) > 0 && len(external) > 0 {
+		sb.WriteString("\n")
+	}
+	imp.render(&sb, external)
+	sb.WriteString(")\n")
+	return sb.String()
+}
+
+// render writes an import spec for each of the given paths.
+func (imp *Imports) render(sb *strings.Builder, paths []string) {
+	for _, path := range paths {
+		sb.WriteString("\t")
+		if alias := imp.aliases[path]; !imp.implied(path, alias) {
+			sb.WriteString(alias)
+			sb.WriteString(" ")
+		}
+		sb.WriteString(strconv.Quote(path))
+		sb.WriteString("\n")
+	}
 }
 
-// _keywords is a set of the Go language keywords.
-var _keywords = map[string]struct{}{
-	"break":       struct{}{},
-	"case":        struct{}{},
-	"chan":        struct{}{},
-	"const":       struct{}{},
-	"continue":    struct{}{},
-	"default":     struct{}{},
-	"defer":       struct{}{},
-	"else":        struct{}{},
-	"fallthrough": struct{}{},
-	"for":         struct{}{},
-	"func":        struct{}{},
-	"go":          struct{}{},
-	"goto":        struct{}{},
-	"if":          struct{}{},
-	"import":      struct{}{},
-	"interface":   struct{}{},
-	"map":         struct{}{},
-	"package":     struct{}{},
-	"range":       struct{}{},
-	"return":      struct{}{},
-	"select":      struct{}{},
-	"struct":      struct{}{},
-	"switch":      struct{}{},
-	"type":        struct{}{},
-	"var":         struct{}{},
+// Len returns the number of registered paths.
+func (imp *Imports) Len() int {
+	return len(imp.aliases)
+}
+
+// Partition splits the registered paths into standard library and
+// external paths, each sorted lexically.
+func (imp *Imports) Partition() (std, external []string) {
+	for _, path := range imp.Paths() {
+		if imp.isStd(path) {
+			std = append(std, path)
+			continue
+		}
+		external = append(external, path)
+	}
+	return std, external
+}
+
+// isStd returns whether the given import path belongs to the
+// standard library, i.e. it was added with AddStd, or its first
+// path element does not contain a dot, and it is not within the
+// Module.
+func (imp *Imports) isStd(path string) bool {
+	if _, ok := imp.std[path]; ok {
+		return true
+	}
+	if imp.Module != "" && (path == imp.Module || strings.HasPrefix(path, imp.Module+"/")) {
+		return false
+	}
+	elem := path
+	if i := strings.Index(path, "/"); i >= 0 {
+		elem = path[:i]
+	}
+	return !strings.Contains(elem, ".")
 }
 
 // RemoveUnusedImports parses the buffer, interpreting it as Go code,
-// and removes all unused imports. If successful, the result is then
-// formatted.
+// and removes all unused imports. Blank (_) and dot (.) imports,
+// as well as the cgo "C" import, are always preserved. The doc
+// and line comments of the remaining imports are retained, and
+// those of the removed imports are removed with them. If any
+// imports are removed, the result is then formatted. Otherwise,
+// the buffer is returned unchanged.
+//
+// As an optimization, the buffer is first scanned (but not parsed),
+// and is returned unchanged if every import is evidently used. In
+// that case, syntax errors that are only detected by the parser
+// are not reported.
 func RemoveUnusedImports(filename string, buf []byte) ([]byte, error) {
+	return RemoveUnusedImportsExcept(filename, buf, nil)
+}
+
+// RemoveUnusedImportsStream is like RemoveUnusedImports, but reads
+// the Go code from r, and writes the result to w. The code is read
+// in full before it is parsed.
+func RemoveUnusedImportsStream(filename string, r io.Reader, w io.Writer) error {
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	out, err := RemoveUnusedImports(filename, buf)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+// RemoveUnusedImportsPackage applies RemoveUnusedImports to each
+// of the given files, keyed by filename, and returns the results
+// keyed in the same way. Each file is still evaluated on its own.
+// If any file fails, an error describing every failure (in filename
+// order) is returned instead.
+func RemoveUnusedImportsPackage(files map[string][]byte) (map[string][]byte, error) {
+	filenames := make([]string, 0, len(files))
+	for filename := range files {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	var (
+		results = make(map[string][]byte, len(files))
+		errs    []error
+	)
+	for _, filename := range filenames {
+		buf, err := RemoveUnusedImports(filename, files[filename])
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		results[filename] = buf
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return results, nil
+}
+
+// RemoveUnusedImportsExcept is like RemoveUnusedImports, but never
+// removes the imports with the given paths, even if they are unused.
+func RemoveUnusedImportsExcept(filename string, buf []byte, keep []string) ([]byte, error) {
+	if usesImports(buf, keep) {
+		return buf, nil
+	}
 	fset := token.NewFileSet()
-	f, err := parser.ParseFile(fset, filename, buf, parser.ParseComments)
+	f, err := parseFile(fset, filename, buf)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse Go code: %v", err)
+		return nil, err
+	}
+	unused, err := unusedImports(f, keep)
+	if err != nil {
+		return nil, err
+	}
+	if len(unused) == 0 {
+		return buf, nil
+	}
+	// The comments attached to the removed imports are removed
+	// with them, so that they aren't left behind in the output.
+	var (
+		file     = fset.File(f.Pos())
+		orphaned = make(map[*ast.CommentGroup]struct{})
+	)
+	for _, decl := range f.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT && gen.Doc != nil {
+			orphaned[gen.Doc] = struct{}{}
+		}
+	}
+	for _, u := range unused {
+		if u.spec.Doc != nil {
+			orphaned[u.spec.Doc] = struct{}{}
+			// Merge the doc comment's lines into the spec's
+			// line so that no blank line is left in its place.
+			start, end := file.Line(u.spec.Doc.Pos()), file.Line(u.spec.Pos())
+			for line := start; line < end; line++ {
+				file.MergeLine(start)
+			}
+		}
+		if u.spec.Comment != nil {
+			orphaned[u.spec.Comment] = struct{}{}
+		}
+		astutil.DeleteNamedImport(fset, f, u.name, u.path)
+	}
+	for _, decl := range f.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT && gen.Doc != nil {
+			// The declaration still has imports, so its
+			// doc comment is retained.
+			delete(orphaned, gen.Doc)
+		}
+	}
+	comments := f.Comments[:0]
+	for _, c := range f.Comments {
+		if _, ok := orphaned[c]; !ok {
+			comments = append(comments, c)
+		}
+	}
+	f.Comments = comments
+	return formatFile(fset, f)
+}
+
+// RemoveUnusedImportsPreserveGroups is like RemoveUnusedImports,
+// but removes each unused import spec from the buffer line by line
+// rather than rewriting the import declarations. The remaining
+// specs keep their original grouping and comments. Import specs
+// are expected to be written on their own lines, as gofmt does;
+// if an unused spec shares a line with anything that's kept, the
+// buffer is rewritten by RemoveUnusedImports instead. If no imports
+// are removed, the buffer is returned unchanged.
+func RemoveUnusedImportsPreserveGroups(filename string, buf []byte) ([]byte, error) {
+	if usesImports(buf, nil) {
+		return buf, nil
+	}
+	fset := token.NewFileSet()
+	f, err := parseFile(fset, filename, buf)
+	if err != nil {
+		return nil, err
+	}
+	unused, err := unusedImports(f, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(unused) == 0 {
+		return buf, nil
+	}
+	remove := make(map[*ast.ImportSpec]struct{}, len(unused))
+	for _, u := range unused {
+		remove[u.spec] = struct{}{}
+	}
+
+	var (
+		file = fset.File(f.Pos())
+		cuts []lineRange
+		kept = []lineRange{newLineRange(file, len(buf), f.Package, f.Name.End())}
+	)
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			kept = append(kept, newLineRange(file, len(buf), decl.Pos(), decl.End()))
+			continue
+		}
+		var specs []lineRange
+		for _, spec := range gen.Specs {
+			spec := spec.(*ast.ImportSpec)
+			if _, ok := remove[spec]; !ok {
+				kept = append(kept, newLineRange(file, len(buf), spec.Pos(), spec.End()))
+				continue
+			}
+			start, end := spec.Pos(), spec.End()
+			if spec.Doc != nil {
+				start = spec.Doc.Pos()
+			}
+			if spec.Comment != nil {
+				end = spec.Comment.End()
+			}
+			specs = append(specs, newLineRange(file, len(buf), start, end))
+		}
+		if len(specs) < len(gen.Specs) {
+			if gen.Lparen.IsValid() {
+				kept = append(kept, newLineRange(file, len(buf), gen.Pos(), gen.Lparen))
+				kept = append(kept, newLineRange(file, len(buf), gen.Rparen, gen.End()))
+			}
+			cuts = append(cuts, specs...)
+			continue
+		}
+		// Every spec in the declaration is unused, so the
+		// entire declaration is removed.
+		start := gen.Pos()
+		if gen.Doc != nil {
+			start = gen.Doc.Pos()
+		}
+		cuts = append(cuts, newLineRange(file, len(buf), start, gen.End()))
+	}
+
+	// A comment that is only partially covered by the cuts would be
+	// cut in half, so it's kept like anything else.
+	for _, c := range f.Comments {
+		r := newLineRange(file, len(buf), c.Pos(), c.End())
+		if !r.within(cuts) {
+			kept = append(kept, r)
+		}
+	}
+
+	var (
+		out    []byte
+		offset int
+	)
+	for _, cut := range cuts {
+		if cut.start < offset || cut.overlaps(kept) {
+			// The unused import shares a line with something
+			// that's kept (e.g. import "fmt"; import "os"), so
+			// it can't be removed line by line.
+			return RemoveUnusedImports(filename, buf)
+		}
+		out = append(out, buf[offset:cut.start]...)
+		offset = cut.end
+	}
+	out = append(out, buf[offset:]...)
+	return Format(filename, out)
+}
+
+// lineRange is a range of byte offsets that spans whole lines.
+type lineRange struct {
+	start int
+	end   int
+}
+
+// overlaps reports whether the lineRange overlaps any of the given
+// ranges.
+func (r lineRange) overlaps(ranges []lineRange) bool {
+	for _, other := range ranges {
+		if r.start < other.end && other.start < r.end {
+			return true
+		}
 	}
+	return false
+}
+
+// within reports whether the lineRange is contained by one of the
+// given ranges.
+func (r lineRange) within(ranges []lineRange) bool {
+	for _, other := range ranges {
+		if other.start <= r.start && r.end <= other.end {
+			return true
+		}
+	}
+	return false
+}
+
+// newLineRange returns the range of the lines that contain the
+// given positions, including the final newline.
+func newLineRange(file *token.File, size int, start, end token.Pos) lineRange {
+	r := lineRange{
+		start: file.Offset(file.LineStart(file.Line(start))),
+		end:   size,
+	}
+	if line := file.Line(end); line < file.LineCount() {
+		r.end = file.Offset(file.LineStart(line + 1))
+	}
+	return r
+}
+
+// unusedImport is an import that is not used by its file.
+type unusedImport struct {
+	spec *ast.ImportSpec
+	name string
+	path string
+}
 
-	imports := make(map[string]string)
+// unusedImports returns the imports that are not used by the
+// given file. Blank (_) and dot (.) imports, as well as the cgo
+// "C" import, are never considered unused, nor are the imports
+// with the paths in keep.
+func unusedImports(f *ast.File, keep []string) ([]unusedImport, error) {
+	kept := make(map[string]struct{}, len(keep))
+	for _, path := range keep {
+		kept[path] = struct{}{}
+	}
+	used := selectorNames(f)
+	var unused []unusedImport
 	for _, route := range f.Imports {
 		importPath, err := strconv.Unquote(route.Path.Value)
 		if err != nil {
@@ -141,19 +948,242 @@ func RemoveUnusedImports(filename string, buf []byte) ([]byte, error) {
 			// the unquote will never fail.
 			return nil, err
 		}
-		imports[route.Name.Name] = importPath
+		// Unnamed imports have a nil Name, and are
+		// represented by the empty string in astutil.
+		var name string
+		if route.Name != nil {
+			name = route.Name.Name
+		}
+		if name == "_" || name == "." || importPath == "C" {
+			// Blank imports are used for their side effects,
+			// and the usage of dot imports can't be tracked
+			// reliably, so neither are ever removed. The cgo
+			// pseudo-import carries its preamble, so it is
+			// never removed either.
+			continue
+		}
+		if _, ok := kept[importPath]; ok {
+			continue
+		}
+		// Each import spec is evaluated independently by its
+		// own name, even if it shares its path with another
+		// import.
+		var ok bool
+		for _, n := range importNames(route, importPath) {
+			if _, ok = used[n]; ok {
+				break
+			}
+		}
+		if !ok {
+			unused = append(unused, unusedImport{
+				spec: route,
+				name: name,
+				path: importPath,
+			})
+		}
 	}
+	return unused, nil
+}
+
+// importNames returns the names that the given import spec may be
+// referred to by. A named import is only referred to by its name.
+// The name of an unnamed import can't be known without loading the
+// package, so both its final path element and its PackageName are
+// accepted, e.g. "v1" and "core" for "k8s.io/api/core/v1".
+func importNames(spec *ast.ImportSpec, path string) []string {
+	if spec.Name != nil {
+		return []string{spec.Name.Name}
+	}
+	return []string{path[strings.LastIndex(path, "/")+1:], PackageName(path)}
+}
 
-	for name, path := range imports {
-		if !astutil.UsesImport(f, path) {
-			astutil.DeleteNamedImport(fset, f, name, path)
+// selectorNames returns the names of the undeclared identifiers
+// that are used as the operand of a selector expression in the
+// given file, i.e. the names that may refer to an import.
+func selectorNames(f *ast.File) map[string]struct{} {
+	unresolved := make(map[*ast.Ident]struct{}, len(f.Unresolved))
+	for _, ident := range f.Unresolved {
+		unresolved[ident] = struct{}{}
+	}
+	names := make(map[string]struct{})
+	ast.Inspect(f, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if ident, ok := sel.X.(*ast.Ident); ok {
+			if _, ok := unresolved[ident]; ok {
+				names[ident.Name] = struct{}{}
+			}
+		}
+		return true
+	})
+	return names
+}
+
+// usesImports scans the buffer, and reports whether every import
+// that may be removed by unusedImports is evidently used, so that
+// the buffer doesn't need to be parsed. An import is evidently used
+// if its name is used as the operand of a selector, and is never
+// used in any other way (e.g. to declare a variable that shadows
+// it). An unnamed import is assumed to be named by the final element
+// of its path, which unusedImports accepts as well. If the buffer
+// can't be scanned, or its imports are unconventional, false is
+// returned so that the buffer is parsed instead.
+func usesImports(buf []byte, keep []string) bool {
+	var (
+		s      scanner.Scanner
+		failed bool
+	)
+	file := token.NewFileSet().AddFile("", -1, len(buf))
+	s.Init(file, buf, func(token.Position, string) { failed = true }, 0)
+	next := func() (token.Token, string) {
+		_, tok, lit := s.Scan()
+		return tok, lit
+	}
+	for _, want := range []token.Token{token.PACKAGE, token.IDENT, token.SEMICOLON} {
+		if tok, _ := next(); tok != want {
+			return false
 		}
 	}
 
-	var buffer bytes.Buffer
-	if err := format.Node(&buffer, fset, f); err != nil {
-		return nil, fmt.Errorf("failed to format Go code: %v", err)
+	kept := make(map[string]struct{}, len(keep))
+	for _, path := range keep {
+		kept[path] = struct{}{}
 	}
+	used := make(map[string]bool) // name -> used
+	tok, lit := next()
+	for tok == token.IMPORT {
+		tok, lit = next()
+		grouped := tok == token.LPAREN
+		if grouped {
+			tok, lit = next()
+		}
+		for !grouped || tok != token.RPAREN {
+			var name string
+			switch tok {
+			case token.IDENT:
+				name = lit
+				tok, lit = next()
+			case token.PERIOD:
+				name = "."
+				tok, lit = next()
+			}
+			if tok != token.STRING {
+				return false
+			}
+			path, err := strconv.Unquote(lit)
+			if err != nil {
+				return false
+			}
+			if name == "" {
+				name = path[strings.LastIndex(path, "/")+1:]
+			}
+			if _, ok := kept[path]; !ok && name != "_" && name != "." && path != "C" {
+				used[name] = false
+			}
+			if tok, lit = next(); tok == token.SEMICOLON {
+				tok, lit = next()
+			}
+			if !grouped {
+				break
+			}
+		}
+		if grouped {
+			if tok, lit = next(); tok == token.SEMICOLON {
+				tok, lit = next()
+			}
+		}
+	}
+
+	// Each use of an import's name must be the operand of a
+	// selector (i.e. preceded by anything other than a period,
+	// and followed by a period).
+	var prev, prevPrev token.Token
+	var prevLit string
+	for ; tok != token.EOF; tok, lit = next() {
+		if _, ok := used[prevLit]; ok && prev == token.IDENT && prevPrev != token.PERIOD {
+			if tok != token.PERIOD {
+				return false
+			}
+			used[prevLit] = true
+		}
+		prevPrev, prev, prevLit = prev, tok, lit
+	}
+	if failed {
+		return false
+	}
+	for _, ok := range used {
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// AddMissingImports parses the buffer, interpreting it as Go code,
+// and adds an import for every selector expression that refers to
+// an alias registered in the given Imports, but not yet imported.
+// This lets code be generated before its imports are reconciled.
+// If successful, the result is then formatted.
+func AddMissingImports(filename string, buf []byte, imp *Imports) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parseFile(fset, filename, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	// The names available in the file scope include the
+	// names of every import already present.
+	names := make(map[string]struct{})
+	for _, route := range f.Imports {
+		importPath, err := strconv.Unquote(route.Path.Value)
+		if err != nil {
+			// Unreachable. If the file parsed successfully,
+			// the unquote will never fail.
+			return nil, err
+		}
+		name := imp.packageName(importPath)
+		if route.Name != nil {
+			name = route.Name.Name
+		}
+		names[name] = struct{}{}
+	}
+
+	// Only identifiers that aren't declared in the file can
+	// refer to an import.
+	unresolved := make(map[*ast.Ident]struct{}, len(f.Unresolved))
+	for _, ident := range f.Unresolved {
+		unresolved[ident] = struct{}{}
+	}
+
+	ast.Inspect(f, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		ident, ok := sel.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		if _, ok := unresolved[ident]; !ok {
+			return true
+		}
+		if _, ok := names[ident.Name]; ok {
+			return true
+		}
+		path, ok := imp.Path(ident.Name)
+		if !ok {
+			return true
+		}
+		if imp.implied(path, ident.Name) {
+			astutil.AddImport(fset, f, path)
+		} else {
+			astutil.AddNamedImport(fset, f, ident.Name, path)
+		}
+		names[ident.Name] = struct{}{}
+		return true
+	})
 
-	return buffer.Bytes(), nil
+	return formatFile(fset, f)
 }
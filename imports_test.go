@@ -0,0 +1,830 @@
+package gospec
+
+import (
+	"go/token"
+	"sync"
+	"testing"
+)
+
+func TestRemoveUnusedImports(t *testing.T) {
+	tests := []struct {
+		desc string
+		give string
+		want string
+	}{
+		{
+			desc: "unused import beside blank import",
+			give: `package p
+
+import (
+	_ "fmt"
+	"fmt"
+)
+`,
+			want: `package p
+
+import (
+	_ "fmt"
+)
+`,
+		},
+		{
+			desc: "unused unnamed import beside used named import",
+			give: `package p
+
+import (
+	"fmt"
+	f "fmt"
+)
+
+var _ = f.Sprint
+`,
+			want: `package p
+
+import (
+	f "fmt"
+)
+
+var _ = f.Sprint
+`,
+		},
+		{
+			desc: "unused named import beside used unnamed import",
+			give: `package p
+
+import (
+	f "fmt"
+	"fmt"
+)
+
+var _ = fmt.Sprint
+`,
+			want: `package p
+
+import (
+	"fmt"
+)
+
+var _ = fmt.Sprint
+`,
+		},
+		{
+			desc: "unnamed import used by its final path element",
+			give: `package p
+
+import (
+	"k8s.io/api/core/v1"
+	"os"
+)
+
+var _ v1.Pod
+`,
+			want: `package p
+
+import (
+	"k8s.io/api/core/v1"
+)
+
+var _ v1.Pod
+`,
+		},
+		{
+			desc: "unused unnamed and named imports",
+			give: `package p
+
+import (
+	"fmt"
+	"os"
+	str "strings"
+)
+
+var _ = fmt.Sprint
+`,
+			want: `package p
+
+import (
+	"fmt"
+)
+
+var _ = fmt.Sprint
+`,
+		},
+		{
+			desc: "blank and dot imports",
+			give: `package p
+
+import (
+	"fmt"
+
+	_ "github.com/lib/pq"
+	. "os"
+)
+
+var _ = fmt.Sprint
+`,
+			want: `package p
+
+import (
+	"fmt"
+
+	_ "github.com/lib/pq"
+	. "os"
+)
+
+var _ = fmt.Sprint
+`,
+		},
+		{
+			desc: "cgo import and build constraints",
+			give: `//go:build linux
+// +build linux
+
+package p
+
+// #include <stdio.h>
+import "C"
+
+import (
+	"fmt"
+	"os"
+)
+
+var _ = fmt.Sprint
+`,
+			want: `//go:build linux
+// +build linux
+
+package p
+
+// #include <stdio.h>
+import "C"
+
+import (
+	"fmt"
+)
+
+var _ = fmt.Sprint
+`,
+		},
+		{
+			desc: "line comments of surviving imports",
+			give: `package p
+
+import (
+	"fmt"     // formatting
+	"os"      // unused
+	"strings" // strings
+)
+
+var _ = fmt.Sprint
+var _ = strings.Cut
+`,
+			want: `package p
+
+import (
+	"fmt"     // formatting
+	"strings" // strings
+)
+
+var _ = fmt.Sprint
+var _ = strings.Cut
+`,
+		},
+		{
+			desc: "doc comments of surviving imports",
+			give: `package p
+
+import (
+	// fmt docs
+	"fmt"
+	// os docs
+	"os"
+	// strings docs
+	"strings"
+)
+
+var _ = fmt.Sprint
+var _ = strings.Cut
+`,
+			want: `package p
+
+import (
+	// fmt docs
+	"fmt"
+	// strings docs
+	"strings"
+)
+
+var _ = fmt.Sprint
+var _ = strings.Cut
+`,
+		},
+		{
+			desc: "doc comment of a removed declaration",
+			give: `package p
+
+// imports
+import (
+	"os" // unused
+)
+
+// Foo is a variable.
+var Foo = 1
+`,
+			want: `package p
+
+// Foo is a variable.
+var Foo = 1
+`,
+		},
+		{
+			desc: "unformatted input without unused imports",
+			give: `package p
+import ( "fmt" )
+var _ =   fmt.Sprint
+`,
+			want: `package p
+import ( "fmt" )
+var _ =   fmt.Sprint
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := RemoveUnusedImports("p.go", []byte(tt.give))
+			if err != nil {
+				t.Fatalf("RemoveUnusedImports() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("RemoveUnusedImports() =\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportsRender(t *testing.T) {
+	tests := []struct {
+		desc     string
+		paths    []string
+		resolver func(string) (string, bool)
+		want     string
+	}{
+		{
+			desc:  "alias is the final path element",
+			paths: []string{"encoding/json", "github.com/foo/bar"},
+			want: `import (
+	"encoding/json"
+
+	"github.com/foo/bar"
+)
+`,
+		},
+		{
+			desc:  "alias differs from the final path element",
+			paths: []string{"k8s.io/api/core/v1", "gopkg.in/yaml.v2"},
+			want: `import (
+	yaml "gopkg.in/yaml.v2"
+	core "k8s.io/api/core/v1"
+)
+`,
+		},
+		{
+			desc:  "alias confirmed by the resolver",
+			paths: []string{"gopkg.in/yaml.v2"},
+			resolver: func(string) (string, bool) {
+				return "yaml", true
+			},
+			want: `import (
+	"gopkg.in/yaml.v2"
+)
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			imp := NewImports("p")
+			imp.Resolver = tt.resolver
+			for _, path := range tt.paths {
+				imp.Add(path)
+			}
+			if got := imp.Render(); got != tt.want {
+				t.Errorf("Render() =\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemoveUnusedImportsPreserveGroups(t *testing.T) {
+	tests := []struct {
+		desc string
+		give string
+		want string
+	}{
+		{
+			desc: "unused import on its own line",
+			give: `package p
+
+import (
+	"fmt"
+
+	// os is unused.
+	"os"
+)
+
+var _ = fmt.Sprint
+`,
+			want: `package p
+
+import (
+	"fmt"
+)
+
+var _ = fmt.Sprint
+`,
+		},
+		{
+			desc: "unused import declaration sharing a line",
+			give: `package p
+
+import "fmt"; import "os"
+
+var _ = os.Exit
+`,
+			want: `package p
+
+import "os"
+
+var _ = os.Exit
+`,
+		},
+		{
+			desc: "unused import spec sharing a line",
+			give: `package p
+
+import ("fmt"; "os")
+
+var _ = fmt.Sprint
+`,
+			want: `package p
+
+import (
+	"fmt"
+)
+
+var _ = fmt.Sprint
+`,
+		},
+		{
+			desc: "unused import sharing a line with a comment",
+			give: `package p
+
+import (
+	"fmt"
+	/* a
+	b */ "os"
+)
+
+var _ = fmt.Sprint
+`,
+			want: `package p
+
+import (
+	"fmt"
+)
+
+var _ = fmt.Sprint
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := RemoveUnusedImportsPreserveGroups("p.go", []byte(tt.give))
+			if err != nil {
+				t.Fatalf("RemoveUnusedImportsPreserveGroups() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("RemoveUnusedImportsPreserveGroups() =\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportsAdd(t *testing.T) {
+	tests := []struct {
+		desc             string
+		paths            []string
+		allowPredeclared bool
+		want             []string
+	}{
+		{
+			desc:  "major version suffix",
+			paths: []string{"github.com/foo/bar/v2"},
+			want:  []string{"bar"},
+		},
+		{
+			desc:  "multi-digit major version suffix",
+			paths: []string{"github.com/foo/bar/v10"},
+			want:  []string{"bar"},
+		},
+		{
+			desc:  "element that begins like a version",
+			paths: []string{"github.com/foo/v2foo"},
+			want:  []string{"v2foo"},
+		},
+		{
+			desc:  "major version suffix collision",
+			paths: []string{"github.com/baz/bar", "github.com/foo/bar/v2"},
+			want:  []string{"bar", "foobar"},
+		},
+		{
+			desc:  "hyphenated element",
+			paths: []string{"github.com/foo/go-kit"},
+			want:  []string{"gokit"},
+		},
+		{
+			desc:  "hyphenated element with a major version suffix",
+			paths: []string{"github.com/grpc-ecosystem/go-grpc-middleware/v2"},
+			want:  []string{"gogrpcmiddleware"},
+		},
+		{
+			desc:  "predeclared identifier",
+			paths: []string{"github.com/foo/error", "github.com/bar/string"},
+			want:  []string{"fooerror", "barstring"},
+		},
+		{
+			desc:             "allowed predeclared identifier",
+			paths:            []string{"github.com/foo/error"},
+			allowPredeclared: true,
+			want:             []string{"error"},
+		},
+		{
+			desc:  "elements without identifier characters",
+			paths: []string{"...", "foo/...", "../..", "%%"},
+			want:  []string{"x", "foo", "x2", "x3"},
+		},
+		{
+			desc:  "element that begins with a digit",
+			paths: []string{"2fa", "b/2fa"},
+			want:  []string{"x2fa", "b2fa"},
+		},
+		{
+			desc:  "package name",
+			paths: []string{"encoding/json", "github.com/foo/p"},
+			want:  []string{"json", "foop"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			imp := NewImports("p")
+			imp.AllowPredeclared = tt.allowPredeclared
+			for i, path := range tt.paths {
+				if got := imp.Add(path); got != tt.want[i] {
+					t.Errorf("Add(%q) = %q, want %q", path, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestImportsClone(t *testing.T) {
+	imp := NewImports("p")
+	imp.KnownAliases = map[string]string{"github.com/foo/bar": "foobar"}
+	imp.Add("fmt")
+
+	clone := imp.Clone()
+	clone.Add("os")
+	clone.KnownAliases["github.com/foo/baz"] = "foobaz"
+	imp.Add("strings")
+	imp.KnownAliases["github.com/foo/qux"] = "fooqux"
+
+	if imp.Has("os") {
+		t.Error("original has the path added to the clone")
+	}
+	if clone.Has("strings") {
+		t.Error("clone has the path added to the original")
+	}
+	if _, ok := imp.KnownAliases["github.com/foo/baz"]; ok {
+		t.Error("original has the known alias added to the clone")
+	}
+	if _, ok := clone.KnownAliases["github.com/foo/qux"]; ok {
+		t.Error("clone has the known alias added to the original")
+	}
+	if got := clone.Add("github.com/foo/bar"); got != "foobar" {
+		t.Errorf("clone.Add() = %q, want %q", got, "foobar")
+	}
+}
+
+func TestImportsAddModuleVersion(t *testing.T) {
+	tests := []struct {
+		give      string
+		wantAlias string
+		wantPath  string
+	}{
+		{
+			give:      "github.com/foo/bar@v1.2.3",
+			wantAlias: "bar",
+			wantPath:  "github.com/foo/bar",
+		},
+		{
+			give:      "gopkg.in/yaml.v2@v2.4.0",
+			wantAlias: "yaml",
+			wantPath:  "gopkg.in/yaml.v2",
+		},
+		{
+			give:      "a/@v1.2.3",
+			wantAlias: "a",
+			wantPath:  "a",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.give, func(t *testing.T) {
+			imp := NewImports("p")
+			if got := imp.Add(tt.give); got != tt.wantAlias {
+				t.Errorf("Add(%q) = %q, want %q", tt.give, got, tt.wantAlias)
+			}
+			if got, ok := imp.Path(tt.wantAlias); !ok || got != tt.wantPath {
+				t.Errorf("Path(%q) = %q, want %q", tt.wantAlias, got, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestUsesImports(t *testing.T) {
+	tests := []struct {
+		desc string
+		give string
+		want bool
+	}{
+		{
+			desc: "selector operands",
+			give: `package p
+
+import (
+	"fmt"
+	_ "github.com/lib/pq"
+)
+
+var _ = fmt.Sprint
+`,
+			want: true,
+		},
+		{
+			desc: "short variable declaration",
+			give: `package p
+
+import "fmt"
+
+func f() {
+	fmt := fmt.Sprint
+	_ = fmt
+}
+`,
+		},
+		{
+			desc: "parameter",
+			give: `package p
+
+import "fmt"
+
+var _ = fmt.Sprint
+
+func f(fmt int) {}
+`,
+		},
+		{
+			desc: "selector on a field",
+			give: `package p
+
+import "fmt"
+
+var _ = x.fmt.y
+`,
+		},
+		{
+			desc: "label",
+			give: `package p
+
+import "fmt"
+
+func f() {
+fmt:
+	fmt.Println()
+	goto fmt
+}
+`,
+		},
+		{
+			desc: "composite literal key",
+			give: `package p
+
+import "fmt"
+
+var _ = fmt.Sprint
+var _ = map[string]int{fmt: 1}
+`,
+		},
+		{
+			desc: "major version suffix",
+			give: `package p
+
+import "github.com/foo/bar/v2"
+
+var _ = bar.X
+`,
+		},
+		{
+			desc: "unformatted",
+			give: `package p
+import ( "fmt" )
+var _ =   fmt.Sprint
+`,
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got := usesImports([]byte(tt.give), nil)
+			if got != tt.want {
+				t.Errorf("usesImports() = %v, want %v", got, tt.want)
+			}
+			if !got {
+				return
+			}
+			// The fast path must agree with unusedImports.
+			f, err := parseFile(token.NewFileSet(), "p.go", []byte(tt.give))
+			if err != nil {
+				t.Fatalf("parseFile() error = %v", err)
+			}
+			unused, err := unusedImports(f, nil)
+			if err != nil {
+				t.Fatalf("unusedImports() error = %v", err)
+			}
+			if len(unused) > 0 {
+				t.Errorf("unusedImports() = %v, want none", unused)
+			}
+		})
+	}
+}
+
+func TestAddMissingImports(t *testing.T) {
+	tests := []struct {
+		desc  string
+		paths []string
+		give  string
+		want  string
+	}{
+		{
+			desc:  "named and unnamed imports",
+			paths: []string{"encoding/json", "k8s.io/api/core/v1"},
+			give: `package p
+
+var _ = json.Marshal
+var _ core.Pod
+`,
+			want: `package p
+
+import (
+	"encoding/json"
+	core "k8s.io/api/core/v1"
+)
+
+var _ = json.Marshal
+var _ core.Pod
+`,
+		},
+		{
+			desc:  "name satisfied by an existing import",
+			paths: []string{"github.com/foo/fmt"},
+			give: `package p
+
+import "fmt"
+
+var _ = fmt.Sprint
+`,
+			want: `package p
+
+import "fmt"
+
+var _ = fmt.Sprint
+`,
+		},
+		{
+			desc:  "selector on a local variable",
+			paths: []string{"encoding/json"},
+			give: `package p
+
+func f(json struct{ Marshal int }) int {
+	return json.Marshal
+}
+`,
+			want: `package p
+
+func f(json struct{ Marshal int }) int {
+	return json.Marshal
+}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			imp := NewImports("p")
+			imp.AddAll(tt.paths...)
+			got, err := AddMissingImports("p.go", []byte(tt.give), imp)
+			if err != nil {
+				t.Fatalf("AddMissingImports() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("AddMissingImports() =\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportsMerge(t *testing.T) {
+	t.Run("conflicting explicit alias", func(t *testing.T) {
+		imp := NewImports("p")
+		imp.Add("github.com/foo/bar")
+		other := NewImports("p")
+		other.Add("github.com/foo/baz")
+		if _, err := other.AddWithAlias("github.com/foo/bar", "foobar"); err != nil {
+			t.Fatalf("AddWithAlias() error = %v", err)
+		}
+		if err := imp.Merge(other); err == nil {
+			t.Fatal("Merge() = nil, want error")
+		}
+		if imp.Has("github.com/foo/baz") || imp.Len() != 1 {
+			t.Errorf("Merge() added paths %q, want none", imp.Paths())
+		}
+	})
+	t.Run("explicit alias", func(t *testing.T) {
+		imp := NewImports("p")
+		imp.Add("github.com/a/json")
+		other := NewImports("p")
+		if _, err := other.AddWithAlias("encoding/json", "stdjson"); err != nil {
+			t.Fatalf("AddWithAlias() error = %v", err)
+		}
+		if err := imp.Merge(other); err != nil {
+			t.Fatalf("Merge() error = %v", err)
+		}
+		if got, ok := imp.Path("stdjson"); !ok || got != "encoding/json" {
+			t.Errorf("Path(%q) = %q, want %q", "stdjson", got, "encoding/json")
+		}
+	})
+	t.Run("alias collision", func(t *testing.T) {
+		imp := NewImports("p")
+		imp.Add("github.com/a/json")
+		other := NewImports("p")
+		other.Add("encoding/json")
+		if err := imp.Merge(other); err != nil {
+			t.Fatalf("Merge() error = %v", err)
+		}
+		if got, ok := imp.Path("json"); !ok || got != "github.com/a/json" {
+			t.Errorf("Path(%q) = %q, want %q", "json", got, "github.com/a/json")
+		}
+		if got, ok := imp.Path("encodingjson"); !ok || got != "encoding/json" {
+			t.Errorf("Path(%q) = %q, want %q", "encodingjson", got, "encoding/json")
+		}
+	})
+}
+
+func TestNewSyncImports(t *testing.T) {
+	imp := NewImports("foo")
+	imp.KnownAliases = map[string]string{"k8s.io/api/core/v1": "corev1"}
+	imp.Add("fmt")
+	s := NewSyncImports(imp)
+	imp.Add("os")
+	s.Reserve("bar")
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "k8s.io/api/core/v1", want: "corev1"},
+		{path: "github.com/a/foo", want: "afoo"},
+		{path: "github.com/a/bar", want: "abar"},
+		{path: "fmt", want: "fmt"},
+	}
+	var wg sync.WaitGroup
+	for _, tt := range tests {
+		wg.Add(1)
+		go func(path, want string) {
+			defer wg.Done()
+			if got := s.Add(path); got != want {
+				t.Errorf("Add(%q) = %q, want %q", path, got, want)
+			}
+		}(tt.path, tt.want)
+	}
+	wg.Wait()
+	if s.Has("os") {
+		t.Error("SyncImports has the path added to the original Imports")
+	}
+}
+
+func TestNewImports(t *testing.T) {
+	if got := NewImports().Add("encoding/json"); got != "json" {
+		t.Errorf("NewImports().Add() = %q, want %q", got, "json")
+	}
+	if got := NewImports("json").Add("encoding/json"); got != "encodingjson" {
+		t.Errorf("NewImports(%q).Add() = %q, want %q", "json", got, "encodingjson")
+	}
+}
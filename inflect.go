@@ -0,0 +1,204 @@
+package gospec
+
+import "strings"
+
+// Plural returns a copy of the Identifier with its last word
+// replaced by its English plural form:
+//
+//	user     -> users
+//	category -> categories
+//	bus      -> buses
+//	person   -> people
+//
+// Words that can't be pluralized by suffix (such as "person") are
+// looked up in a small table of irregular words. Uncountable words
+// (such as "metadata"), and words that are already plural, are left
+// unchanged.
+func (i Identifier) Plural() *Identifier {
+	return i.PluralWith(nil)
+}
+
+// PluralWith is like Plural, but consults the given irregular
+// words, mapped from singular to plural, before the defaults.
+//
+//	NewIdentifier("index").PluralWith(map[string]string{"index": "indexes"}) -> "indexes"
+func (i Identifier) PluralWith(irregulars map[string]string) *Identifier {
+	return i.inflectLast(func(word string) string {
+		return pluralize(word, irregulars)
+	})
+}
+
+// Singular returns a copy of the Identifier with its last word
+// replaced by its English singular form. It is the inverse of
+// Plural, and words that are already singular are left unchanged.
+func (i Identifier) Singular() *Identifier {
+	return i.SingularWith(nil)
+}
+
+// SingularWith is like Singular, but consults the given irregular
+// words, mapped from singular to plural, before the defaults.
+func (i Identifier) SingularWith(irregulars map[string]string) *Identifier {
+	return i.inflectLast(func(word string) string {
+		return singularize(word, irregulars)
+	})
+}
+
+// inflectLast returns a new Identifier with the given function
+// applied to its last word.
+func (i Identifier) inflectLast(inflect func(string) string) *Identifier {
+	if len(i.Words) == 0 {
+		return &i
+	}
+	words := make([]string, len(i.Words))
+	copy(words, i.Words)
+	words[len(words)-1] = inflect(words[len(words)-1])
+	return i.withWords(words)
+}
+
+// pluralize returns the plural form of the given word.
+func pluralize(word string, irregulars map[string]string) string {
+	// A word that is already plural is left unchanged, so that
+	// e.g. "users" doesn't become "userses".
+	if singular := singularize(word, irregulars); singular != word && pluralForm(singular, irregulars) == word {
+		return word
+	}
+	return pluralForm(word, irregulars)
+}
+
+// pluralForm returns the plural form of the given singular word.
+func pluralForm(word string, irregulars map[string]string) string {
+	if _, ok := _uncountables[word]; ok {
+		return word
+	}
+	if plural, ok := irregulars[word]; ok {
+		return plural
+	}
+	if plural, ok := _irregulars[word]; ok {
+		return plural
+	}
+	switch {
+	case hasConsonantY(word):
+		return word[:len(word)-1] + "ies"
+	case hasSibilant(word):
+		return word + "es"
+	}
+	return word + "s"
+}
+
+// singularize returns the singular form of the given word.
+func singularize(word string, irregulars map[string]string) string {
+	if _, ok := _uncountables[word]; ok {
+		return word
+	}
+	// A word that is already singular is left unchanged, so that
+	// e.g. "status" doesn't become "statu".
+	if _, ok := irregulars[word]; ok {
+		return word
+	}
+	if _, ok := _irregulars[word]; ok {
+		return word
+	}
+	for _, table := range []map[string]string{irregulars, _irregulars} {
+		for singular, plural := range table {
+			if plural == word {
+				return singular
+			}
+		}
+	}
+	switch {
+	case strings.HasSuffix(word, "ies") && len(word) > 3:
+		return word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "es") && hasPluralSibilant(word[:len(word)-2]):
+		return word[:len(word)-2]
+	case strings.HasSuffix(word, "s") && !hasSingularS(word):
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+// hasConsonantY returns whether the given word ends with a
+// consonant followed by a 'y', e.g. "category".
+func hasConsonantY(word string) bool {
+	if len(word) < 2 || word[len(word)-1] != 'y' {
+		return false
+	}
+	return !strings.ContainsRune("aeiou", rune(word[len(word)-2]))
+}
+
+// hasPluralSibilant returns whether the given word, stripped of a
+// trailing "es", ends with a sibilant that is pluralized with "es".
+// A single 's' isn't enough, since it's more likely to belong to
+// the singular, e.g. "response" rather than "respons".
+func hasPluralSibilant(word string) bool {
+	for _, suffix := range []string{"ss", "x", "z", "ch", "sh"} {
+		if strings.HasSuffix(word, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasSingularS returns whether the given word ends with an 's' that
+// belongs to its singular form, e.g. "class", "bonus", or "basis".
+func hasSingularS(word string) bool {
+	for _, suffix := range []string{"ss", "us", "is"} {
+		if strings.HasSuffix(word, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasSibilant returns whether the given word ends with a sibilant
+// sound that is pluralized with "es", e.g. "box".
+func hasSibilant(word string) bool {
+	for _, suffix := range []string{"s", "x", "z", "ch", "sh"} {
+		if strings.HasSuffix(word, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// _irregulars maps singular words to their plural form when it
+// cannot be derived by the suffix rules, or when the singular
+// form cannot be derived from the plural.
+var _irregulars = map[string]string{
+	"alias":     "aliases",
+	"analysis":  "analyses",
+	"axis":      "axes",
+	"bus":       "buses",
+	"child":     "children",
+	"criterion": "criteria",
+	"foot":      "feet",
+	"goose":     "geese",
+	"index":     "indices",
+	"knife":     "knives",
+	"leaf":      "leaves",
+	"life":      "lives",
+	"man":       "men",
+	"matrix":    "matrices",
+	"medium":    "media",
+	"movie":     "movies",
+	"mouse":     "mice",
+	"ox":        "oxen",
+	"person":    "people",
+	"quiz":      "quizzes",
+	"status":    "statuses",
+	"tooth":     "teeth",
+	"vertex":    "vertices",
+	"woman":     "women",
+}
+
+// _uncountables is a set of words that have no distinct plural form.
+var _uncountables = map[string]struct{}{
+	"data":        struct{}{},
+	"equipment":   struct{}{},
+	"fish":        struct{}{},
+	"information": struct{}{},
+	"metadata":    struct{}{},
+	"news":        struct{}{},
+	"series":      struct{}{},
+	"sheep":       struct{}{},
+	"species":     struct{}{},
+}
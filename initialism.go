@@ -0,0 +1,73 @@
+package gospec
+
+import "strings"
+
+// CommonInitialisms returns the set of initialisms that are
+// upper-cased by NewIdentifier. A new map is returned on every
+// call, so callers are free to extend it with their own
+// initialisms and supply it to NewIdentifierWithInitialisms.
+//
+// The set is derived from the list used by golint.
+func CommonInitialisms() map[string]bool {
+	initialisms := make(map[string]bool, len(_commonInitialisms))
+	for initialism := range _commonInitialisms {
+		initialisms[initialism] = true
+	}
+	return initialisms
+}
+
+// capitalize returns the upper-case form of the given word if it
+// is a recognized initialism, and its title-case form otherwise.
+// The plural of an initialism is cased accordingly (e.g. "IDs").
+func capitalize(word string, initialisms map[string]bool) string {
+	upper := strings.ToUpper(word)
+	if initialisms[upper] {
+		return upper
+	}
+	if stem := strings.TrimSuffix(upper, "S"); stem != upper && initialisms[stem] {
+		return stem + "s"
+	}
+	return title(word)
+}
+
+// _commonInitialisms is the default set of initialisms.
+var _commonInitialisms = map[string]bool{
+	"ACL":   true,
+	"API":   true,
+	"ASCII": true,
+	"CPU":   true,
+	"CSS":   true,
+	"DNS":   true,
+	"EOF":   true,
+	"GUID":  true,
+	"HTML":  true,
+	"HTTP":  true,
+	"HTTPS": true,
+	"ID":    true,
+	"IP":    true,
+	"JSON":  true,
+	"LHS":   true,
+	"QPS":   true,
+	"RAM":   true,
+	"RHS":   true,
+	"RPC":   true,
+	"SLA":   true,
+	"SMTP":  true,
+	"SQL":   true,
+	"SSH":   true,
+	"TCP":   true,
+	"TLS":   true,
+	"TTL":   true,
+	"UDP":   true,
+	"UI":    true,
+	"UID":   true,
+	"UUID":  true,
+	"URI":   true,
+	"URL":   true,
+	"UTF8":  true,
+	"VM":    true,
+	"XML":   true,
+	"XMPP":  true,
+	"XSRF":  true,
+	"XSS":   true,
+}
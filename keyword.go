@@ -0,0 +1,100 @@
+package gospec
+
+// IsKeyword returns whether the given string is a Go keyword.
+func IsKeyword(s string) bool {
+	_, ok := _keywords[s]
+	return ok
+}
+
+// _keywords is a set of the Go language keywords.
+var _keywords = map[string]struct{}{
+	"break":       struct{}{},
+	"case":        struct{}{},
+	"chan":        struct{}{},
+	"const":       struct{}{},
+	"continue":    struct{}{},
+	"default":     struct{}{},
+	"defer":       struct{}{},
+	"else":        struct{}{},
+	"fallthrough": struct{}{},
+	"for":         struct{}{},
+	"func":        struct{}{},
+	"go":          struct{}{},
+	"goto":        struct{}{},
+	"if":          struct{}{},
+	"import":      struct{}{},
+	"interface":   struct{}{},
+	"map":         struct{}{},
+	"package":     struct{}{},
+	"range":       struct{}{},
+	"return":      struct{}{},
+	"select":      struct{}{},
+	"struct":      struct{}{},
+	"switch":      struct{}{},
+	"type":        struct{}{},
+	"var":         struct{}{},
+}
+
+// IsPredeclared returns whether the given string is one of the Go
+// predeclared identifiers, such as a builtin type, constant, or
+// function. Although they can be shadowed, doing so in generated
+// code is almost always a mistake.
+func IsPredeclared(s string) bool {
+	_, ok := _predeclared[s]
+	return ok
+}
+
+// _predeclared is a set of the Go predeclared identifiers.
+var _predeclared = map[string]struct{}{
+	// Types.
+	"any":        struct{}{},
+	"bool":       struct{}{},
+	"byte":       struct{}{},
+	"comparable": struct{}{},
+	"complex64":  struct{}{},
+	"complex128": struct{}{},
+	"error":      struct{}{},
+	"float32":    struct{}{},
+	"float64":    struct{}{},
+	"int":        struct{}{},
+	"int8":       struct{}{},
+	"int16":      struct{}{},
+	"int32":      struct{}{},
+	"int64":      struct{}{},
+	"rune":       struct{}{},
+	"string":     struct{}{},
+	"uint":       struct{}{},
+	"uint8":      struct{}{},
+	"uint16":     struct{}{},
+	"uint32":     struct{}{},
+	"uint64":     struct{}{},
+	"uintptr":    struct{}{},
+
+	// Constants.
+	"false": struct{}{},
+	"iota":  struct{}{},
+	"true":  struct{}{},
+
+	// Zero value.
+	"nil": struct{}{},
+
+	// Functions.
+	"append":  struct{}{},
+	"cap":     struct{}{},
+	"clear":   struct{}{},
+	"close":   struct{}{},
+	"complex": struct{}{},
+	"copy":    struct{}{},
+	"delete":  struct{}{},
+	"imag":    struct{}{},
+	"len":     struct{}{},
+	"make":    struct{}{},
+	"max":     struct{}{},
+	"min":     struct{}{},
+	"new":     struct{}{},
+	"panic":   struct{}{},
+	"print":   struct{}{},
+	"println": struct{}{},
+	"real":    struct{}{},
+	"recover": struct{}{},
+}
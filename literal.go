@@ -0,0 +1,62 @@
+package gospec
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NewIdentifierWithLiterals is like NewIdentifier, but treats each
+// of the given literal words (e.g. "iOS", "GraphQL") as a single
+// word that is emitted verbatim in the Camel, Pascal, and Title
+// forms. Literals are matched case-insensitively wherever a word
+// may begin, and take precedence over both the default lowercasing
+// of the first Camel word and the CommonInitialisms:
+//
+//	NewIdentifierWithLiterals("iosApp", []string{"iOS"}) -> Camel "iOSApp", Pascal "iOSApp"
+//
+// Literals are useful for mixed-case words that aren't simple
+// initialisms, and may contain digits:
+//
+//	literals := []string{"GraphQL", "OAuth2"}
+//	NewIdentifierWithLiterals("oauth2Provider", literals) -> Pascal "OAuth2Provider"
+//	NewIdentifierWithLiterals("my_graphql_api", literals) -> Pascal "MyGraphQLAPI"
+//	NewIdentifierWithLiterals("graphqlSchema", literals)  -> Camel "GraphQLSchema"
+//
+// The remaining forms, such as Snake, use the lowercase literal.
+func NewIdentifierWithLiterals(s string, literals []string) (*Identifier, error) {
+	return NewIdentifierWithOptions(s, Options{Literals: literals})
+}
+
+// literal returns the length of the literal that begins at the
+// given index, or zero if there is none. A literal only matches
+// where a word may begin, and if it is not immediately followed
+// by the continuation of another word.
+func (p *identParser) literal(runes []rune, i int) int {
+	if len(p.literals) == 0 || !startsWord(runes, i) {
+		return 0
+	}
+	for _, literal := range p.literals {
+		n := len(literal)
+		if i+n > len(runes) || !strings.EqualFold(string(runes[i:i+n]), string(literal)) {
+			continue
+		}
+		if i+n < len(runes) {
+			next := runes[i+n]
+			if unicode.IsLower(next) || (unicode.IsNumber(next) && unicode.IsNumber(literal[n-1])) {
+				continue
+			}
+		}
+		return n
+	}
+	return 0
+}
+
+// startsWord returns true if a new word may begin at the
+// given index.
+func startsWord(runes []rune, i int) bool {
+	if i == 0 || isUpper(runes[i]) {
+		return true
+	}
+	prev := runes[i-1]
+	return !isLower(prev) || (unicode.IsLetter(runes[i]) && unicode.IsNumber(prev))
+}
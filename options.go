@@ -0,0 +1,106 @@
+package gospec
+
+import "strings"
+
+// Options configures how NewIdentifierWithOptions parses a string
+// into an Identifier. The zero value is equivalent to NewIdentifier.
+//
+//	NewIdentifierWithOptions("café_ios_identifier", Options{
+//		ASCIIFold:    true,
+//		Literals:     []string{"iOS"},
+//		Replacements: map[string]string{"identifier": "id"},
+//	}) -> Pascal "CafeiOSID"
+type Options struct {
+	// ASCIIFold folds the string to ASCII before it is parsed,
+	// as in NewIdentifierASCII.
+	ASCIIFold bool
+
+	// BoundaryRunes, if non-nil, are the only punctuation runes
+	// that delimit words. Any other punctuation is removed, so it
+	// doesn't split the words on either side of it. Whitespace
+	// and changes in case still delimit words.
+	//
+	//	NewIdentifierWithOptions("std::user@host", Options{
+	//		BoundaryRunes: map[rune]bool{':': true},
+	//	}) -> Snake "std_userhost"
+	BoundaryRunes map[rune]bool
+
+	// Extensions are the file extensions that are removed from
+	// the string before it is parsed, as in TrimExtension. No
+	// extension is removed if empty; use CommonExtensions for
+	// the default set.
+	Extensions []string
+
+	// Initialisms is the set of initialisms that are upper-cased
+	// in the Camel, Pascal, and Title forms, as in
+	// NewIdentifierWithInitialisms. If nil, the CommonInitialisms
+	// are used; use an empty set to disable initialisms.
+	Initialisms map[string]bool
+
+	// Literals are words that are emitted verbatim, as in
+	// NewIdentifierWithLiterals. Each literal is matched
+	// case-insensitively and emitted exactly as written, so
+	// "OAuth2" matches "oauth2" and produces "OAuth2".
+	Literals []string
+
+	// PreserveUnderscore preserves a leading underscore in every
+	// form, as in NewIdentifierPreservingUnderscore.
+	PreserveUnderscore bool
+
+	// Replacements replaces the parsed words before the forms are
+	// computed, as in NewIdentifierWithReplacements.
+	Replacements map[string]string
+
+	// SplitNumbers treats a digit that follows a letter as the
+	// start of a new word. A letter that follows a digit always
+	// begins a new word, regardless.
+	//
+	//	user123name -> [user123 name]  (default)
+	//	user123name -> [user 123 name] (SplitNumbers)
+	SplitNumbers bool
+}
+
+// NewIdentifierWithOptions is like NewIdentifier, but parses the
+// supplied string according to the given Options. The string has
+// its extension removed, is folded, parsed (matching any literals),
+// and then has its words replaced, in that order. The Source retains
+// the original string.
+func NewIdentifierWithOptions(s string, opts Options) (*Identifier, error) {
+	p := _parsers.Get().(*identParser)
+	defer func() {
+		p.literals = p.literals[:0]
+		p.boundaries = nil
+		_parsers.Put(p)
+	}()
+	p.reset()
+	p.boundaries = opts.BoundaryRunes
+	p.numbers = opts.SplitNumbers
+	c := casing{
+		initialisms: opts.Initialisms,
+		literals:    make(map[string]string, len(opts.Literals)),
+	}
+	if c.initialisms == nil {
+		c.initialisms = _commonInitialisms
+	}
+	for _, literal := range opts.Literals {
+		if literal == "" {
+			continue
+		}
+		p.literals = append(p.literals, []rune(literal))
+		c.literals[strings.ToLower(literal)] = literal
+	}
+	if opts.PreserveUnderscore && strings.HasPrefix(strings.TrimSpace(s), "_") {
+		c.prefix = "_"
+	}
+	parsed := s
+	if len(opts.Extensions) > 0 {
+		parsed = TrimExtension(parsed, opts.Extensions...)
+	}
+	if opts.ASCIIFold {
+		parsed = foldASCII(parsed)
+	}
+	// The parsed words are owned by the pooled parser, so they're
+	// copied before it's reused.
+	words := append([]string(nil), p.parse(parsed)...)
+	return newIdentifier(s, replace(words, opts.Replacements), c)
+}
@@ -0,0 +1,33 @@
+package gospec
+
+import "strconv"
+
+// SlugSet assigns unique slugs, such as file names, to a set of
+// Identifiers. The zero value is ready to use. SlugSet is not safe
+// for concurrent use.
+//
+//	var slugs SlugSet
+//	slugs.Slug(NewIdentifier("userID"))  -> "user-id"
+//	slugs.Slug(NewIdentifier("user_id")) -> "user-id-2"
+//	slugs.Slug(NewIdentifier("UserID"))  -> "user-id-3"
+type SlugSet struct {
+	seen map[string]struct{}
+}
+
+// Slug returns the Kebab form of the given Identifier. If the form
+// has already been returned by the SlugSet, an incrementing integer
+// is appended until the slug is unique.
+func (s *SlugSet) Slug(i Identifier) string {
+	if s.seen == nil {
+		s.seen = make(map[string]struct{})
+	}
+	slug := i.Kebab
+	for n := 2; ; n++ {
+		if _, ok := s.seen[slug]; !ok {
+			break
+		}
+		slug = i.Kebab + "-" + strconv.Itoa(n)
+	}
+	s.seen[slug] = struct{}{}
+	return slug
+}
@@ -0,0 +1,86 @@
+package gospec
+
+import "fmt"
+
+// Style is one of the case conventions of an Identifier.
+type Style int
+
+// The case conventions of an Identifier.
+const (
+	Camel Style = iota
+	Constant
+	Dot
+	Kebab
+	Natural
+	Package
+	Pascal
+	Snake
+	Title
+	Train
+)
+
+// _styleNames maps each Style to its name.
+var _styleNames = [...]string{
+	Camel:    "camel",
+	Constant: "constant",
+	Dot:      "dot",
+	Kebab:    "kebab",
+	Natural:  "natural",
+	Package:  "package",
+	Pascal:   "pascal",
+	Snake:    "snake",
+	Title:    "title",
+	Train:    "train",
+}
+
+// ParseStyle returns the Style with the given name, such as
+// "camel", "pascal", or "snake". An error is returned if the
+// name is not recognized.
+func ParseStyle(name string) (Style, error) {
+	for style, n := range _styleNames {
+		if n == name {
+			return Style(style), nil
+		}
+	}
+	return 0, fmt.Errorf("%q is not a valid case style", name)
+}
+
+// String returns the name of the Style, e.g. "camel".
+func (s Style) String() string {
+	if s < 0 || int(s) >= len(_styleNames) {
+		return fmt.Sprintf("Style(%d)", int(s))
+	}
+	return _styleNames[s]
+}
+
+// In returns the variant of the Identifier in the given case style.
+// Every variant is computed when the Identifier is created, so this
+// is equivalent to reading the corresponding field. The empty string
+// is returned if the style is not recognized.
+//
+//	id.In(gospec.Snake) == id.Snake
+func (i Identifier) In(style Style) string {
+	switch style {
+	case Camel:
+		return i.Camel
+	case Constant:
+		return i.Constant
+	case Dot:
+		return i.Dot
+	case Kebab:
+		return i.Kebab
+	case Natural:
+		return i.Natural
+	case Package:
+		return i.Package
+	case Pascal:
+		return i.Pascal
+	case Snake:
+		return i.Snake
+	case Title:
+		return i.Title
+	case Train:
+		return i.Train
+	}
+	return ""
+}
@@ -0,0 +1,78 @@
+package gospec
+
+import "sync"
+
+// SyncImports is a set of Imports that is safe for concurrent use.
+// The zero value is ready to use; use NewSyncImports to configure
+// its Imports, e.g. with a Package or Resolver.
+type SyncImports struct {
+	mu      sync.RWMutex
+	imports Imports
+}
+
+// NewSyncImports returns a SyncImports that begins with a copy of
+// the given Imports, including its configuration and any paths
+// already registered. Later changes to imp don't affect the
+// SyncImports.
+//
+//	imports := NewImports("foo")
+//	imports.KnownAliases = map[string]string{"k8s.io/api/core/v1": "corev1"}
+//	shared := NewSyncImports(imports)
+func NewSyncImports(imp *Imports) *SyncImports {
+	s := new(SyncImports)
+	if imp != nil {
+		s.imports = *imp.Clone()
+	}
+	return s
+}
+
+// Add adds the path to the imports map. For details, see Imports.Add.
+func (s *SyncImports) Add(path string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.imports.Add(path)
+}
+
+// AddWithAlias adds the path to the imports map using the given alias.
+// For details, see Imports.AddWithAlias.
+func (s *SyncImports) AddWithAlias(path, alias string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.imports.AddWithAlias(path, alias)
+}
+
+// Has returns whether the given path is registered.
+func (s *SyncImports) Has(path string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.imports.Has(path)
+}
+
+// Path returns the import path registered under the given alias.
+func (s *SyncImports) Path(alias string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.imports.Path(alias)
+}
+
+// Reserve prevents the given names from being used as an alias.
+// For details, see Imports.Reserve.
+func (s *SyncImports) Reserve(names ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.imports.Reserve(names...)
+}
+
+// Remove removes the given path from the imports map.
+func (s *SyncImports) Remove(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.imports.Remove(path)
+}
+
+// Imports returns a copy of the underlying imports.
+func (s *SyncImports) Imports() *Imports {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.imports.Clone()
+}
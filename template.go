@@ -0,0 +1,30 @@
+package gospec
+
+import "text/template"
+
+// FuncMap returns a template.FuncMap that exposes each of the
+// Identifier case conventions as a template function. Each
+// function accepts a string and returns its converted form.
+// Registering the functions is all that is needed to use them:
+//
+//	tmpl := template.New("").Funcs(gospec.FuncMap())
+//	tmpl.Parse("type {{pascal .Name}} struct{}")
+func FuncMap() template.FuncMap {
+	funcs := make(template.FuncMap, len(_styleNames))
+	for style, name := range _styleNames {
+		funcs[name] = caseFunc(Style(style))
+	}
+	return funcs
+}
+
+// caseFunc returns a template function that converts its
+// argument into the given case style.
+func caseFunc(style Style) func(string) (string, error) {
+	return func(s string) (string, error) {
+		id, err := NewIdentifier(s)
+		if err != nil {
+			return "", err
+		}
+		return id.In(style), nil
+	}
+}
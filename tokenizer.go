@@ -0,0 +1,31 @@
+package gospec
+
+// Tokenizer splits strings into words using the same rules as
+// ParseWords, but reuses its buffers between calls, so that many
+// strings can be split without allocating a new parser for each.
+// The zero value is ready to use. A Tokenizer is not safe for
+// concurrent use.
+//
+//	var t Tokenizer
+//	for _, name := range names {
+//	  words := t.Words(name)
+//	  ...
+//	}
+type Tokenizer struct {
+	p identParser
+}
+
+// Reset discards the words returned by the previous call to Words,
+// but retains the underlying buffers for reuse.
+func (t *Tokenizer) Reset() {
+	t.p.reset()
+}
+
+// Words splits the given string into its lowercase words. The
+// returned slice is only valid until the next call to Words or
+// Reset, after which its elements are overwritten; copy it if the
+// words need to be retained.
+func (t *Tokenizer) Words(s string) []string {
+	t.Reset()
+	return t.p.parse(s)
+}
@@ -0,0 +1,81 @@
+package gospec
+
+import (
+	"fmt"
+	"regexp"
+	"unicode"
+)
+
+// invalidIdentifier matches invalid identifier characters
+// according to the Go language spec, i.e. anything other than a
+// Unicode letter, a Unicode decimal digit, or an underscore. The
+// POSIX classes are ASCII-only, so the Unicode classes are used.
+var invalidIdentifierChar = regexp.MustCompile(`[^\p{L}\p{Nd}_]`)
+
+// ValidateIdentifier returns an error describing why the given
+// string is not a legal Go identifier, or nil if it is. An
+// identifier must be non-empty, begin with a letter or underscore,
+// consist only of letters, digits, and underscores, and must not
+// be a keyword.
+//
+//	identifier = letter { letter | unicode_digit }
+//
+// For details, see https://golang.org/ref/spec#Identifiers.
+func ValidateIdentifier(s string) error {
+	if s == "" {
+		return fmt.Errorf("invalid identifier %q: cannot be empty", s)
+	}
+	for i, r := range s {
+		if i == 0 && unicode.IsDigit(r) {
+			return fmt.Errorf("invalid identifier %q: cannot start with digit", s)
+		}
+		if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return fmt.Errorf("invalid identifier %q: contains illegal rune %q", s, r)
+		}
+	}
+	if IsKeyword(s) {
+		return fmt.Errorf("invalid identifier %q: cannot be a keyword", s)
+	}
+	return nil
+}
+
+// ToIdentifier coerces the given string into a legal Go identifier.
+// Invalid characters are removed, an underscore is prepended if the
+// result begins with a digit, and an underscore is appended if the
+// result is a keyword. If nothing remains, the blank identifier is
+// returned.
+//
+//	ToIdentifier("first name") -> "firstname"
+//	ToIdentifier("2fast")      -> "_2fast"
+//	ToIdentifier("type")       -> "type_"
+//	ToIdentifier("café")       -> "café"
+//	ToIdentifier("user😀name") -> "username"
+//	ToIdentifier("---")        -> "_"
+func ToIdentifier(s string) string {
+	s = StripInvalidIdentifierChars(s)
+	if s == "" {
+		return "_"
+	}
+	return guardKeyword(guardDigit(s))
+}
+
+// HasInvalidIdentifierChars returns whether the given string
+// contains any character that cannot appear in a Go identifier,
+// i.e. anything other than letters, digits, and underscores.
+// Symbols, such as emoji, are never valid.
+//
+//	HasInvalidIdentifierChars("go-bar") -> true
+//	HasInvalidIdentifierChars("go_bar") -> false
+func HasInvalidIdentifierChars(s string) bool {
+	return invalidIdentifierChar.MatchString(s)
+}
+
+// StripInvalidIdentifierChars removes every character that cannot
+// appear in a Go identifier from the given string. Unlike
+// ToIdentifier, the result may still begin with a digit, be a
+// keyword, or be empty.
+//
+//	StripInvalidIdentifierChars("go-bar.v2") -> "gobarv2"
+func StripInvalidIdentifierChars(s string) string {
+	return invalidIdentifierChar.ReplaceAllString(s, "")
+}